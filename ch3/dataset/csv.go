@@ -0,0 +1,187 @@
+// Package dataset loads tabular data from CSV files into matrices usable by the nn package.
+package dataset
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// NA is the literal used in CSV files for a missing value.
+const NA = "NA"
+
+// NAPolicy decides what happens to rows that contain missing values.
+type NAPolicy int
+
+const (
+	NAFail NAPolicy = iota // Return an *NAError for the first missing value found.
+	NADrop                 // Drop every row that has a missing value in one of the selected columns.
+)
+
+// NAError reports a missing value in a column that was expected to hold a number.
+type NAError struct {
+	Row    int    // Index of the row in the table, not counting the header.
+	Column string // Name of the column.
+}
+
+func (e *NAError) Error() string {
+	return fmt.Sprintf("dataset: missing value in column %q at row %d", e.Column, e.Row)
+}
+
+// Table holds the raw cells of a CSV file with a header row.
+type Table struct {
+	Header []string   // Column names, in file order.
+	Rows   [][]string // Each row has one cell per column of the header.
+}
+
+// Dataset holds input and target matrices ready to be passed to nn.Network.TrainAll.
+type Dataset struct {
+	Inputs      [][]float64 // One row per sample, one column per input name.
+	Targets     [][]float64 // One row per sample, one column per target name.
+	InputNames  []string    // Names of the columns the inputs were read from.
+	TargetNames []string    // Names of the columns the targets were read from.
+	Dropped     []int       // Indices of the table rows that were dropped because of missing values.
+}
+
+// IsNA reports whether a cell holds a missing value.
+func IsNA(cell string) bool {
+	return cell == NA || cell == ""
+}
+
+// ReadCSV reads a CSV document whose first record is the header.
+func ReadCSV(r io.Reader) (*Table, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("dataset: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("dataset: missing header row")
+	}
+	return &Table{Header: records[0], Rows: records[1:]}, nil
+}
+
+// LoadCSV reads the CSV file at path. See ReadCSV.
+func LoadCSV(path string) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadCSV(f)
+}
+
+// Index returns the position of the named column in the header.
+func (t *Table) Index(name string) (int, error) {
+	for i, h := range t.Header {
+		if h == name {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("dataset: unknown column %q", name)
+}
+
+// Column returns a copy of the cells of the named column.
+func (t *Table) Column(name string) ([]string, error) {
+	c, err := t.Index(name)
+	if err != nil {
+		return nil, err
+	}
+	cells := make([]string, len(t.Rows))
+	for i, row := range t.Rows {
+		cells[i] = row[c]
+	}
+	return cells, nil
+}
+
+// DropNA returns a table without the rows that have a missing value in any of the given columns,
+// or in any column at all if none are given. It also returns the indices of the dropped rows.
+func (t *Table) DropNA(columns ...string) (*Table, []int, error) {
+	if len(columns) == 0 {
+		columns = t.Header
+	}
+	indices, err := t.indices(columns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &Table{Header: t.Header}
+	var dropped []int
+	for i, row := range t.Rows {
+		keep := true
+		for _, c := range indices {
+			if IsNA(row[c]) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			result.Rows = append(result.Rows, row)
+		} else {
+			dropped = append(dropped, i)
+		}
+	}
+	return result, dropped, nil
+}
+
+// Floats parses the given columns as numbers. Each row of the result holds one value per column,
+// in the order the columns were given. A missing value yields an *NAError.
+func (t *Table) Floats(columns ...string) ([][]float64, error) {
+	indices, err := t.indices(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([][]float64, len(t.Rows))
+	for i, row := range t.Rows {
+		result[i] = make([]float64, len(indices))
+		for j, c := range indices {
+			if IsNA(row[c]) {
+				return nil, &NAError{Row: i, Column: columns[j]}
+			}
+			v, err := strconv.ParseFloat(row[c], 64)
+			if err != nil {
+				return nil, fmt.Errorf("dataset: column %q at row %d: %w", columns[j], i, err)
+			}
+			result[i][j] = v
+		}
+	}
+	return result, nil
+}
+
+// Dataset extracts the named input and target columns as numbers.
+// Rows with missing values in those columns are handled according to policy.
+func (t *Table) Dataset(inputs, targets []string, policy NAPolicy) (*Dataset, error) {
+	src := t
+	var dropped []int
+	if policy == NADrop {
+		var err error
+		src, dropped, err = t.DropNA(append(append([]string{}, inputs...), targets...)...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	x, err := src.Floats(inputs...)
+	if err != nil {
+		return nil, err
+	}
+	y, err := src.Floats(targets...)
+	if err != nil {
+		return nil, err
+	}
+	return &Dataset{Inputs: x, Targets: y, InputNames: inputs, TargetNames: targets, Dropped: dropped}, nil
+}
+
+func (t *Table) indices(columns []string) ([]int, error) {
+	indices := make([]int, len(columns))
+	for i, name := range columns {
+		c, err := t.Index(name)
+		if err != nil {
+			return nil, err
+		}
+		indices[i] = c
+	}
+	return indices, nil
+}
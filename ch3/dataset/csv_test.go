@@ -0,0 +1,45 @@
+package dataset
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const penguins = `species,island,bill_length_mm,body_mass_g,sex
+Adelie,Torgersen,39.1,3750,male
+Adelie,Torgersen,NA,NA,NA
+Gentoo,Biscoe,46.2,4650,NA
+Chinstrap,Dream,46.5,3500,female
+`
+
+func TestTable_Dataset(t *testing.T) {
+	table, err := ReadCSV(strings.NewReader(penguins))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Test case 1: missing values fail by default.
+	_, err = table.Dataset([]string{"bill_length_mm"}, []string{"body_mass_g"}, NAFail)
+	var naErr *NAError
+	if !errors.As(err, &naErr) || naErr.Row != 1 || naErr.Column != "bill_length_mm" {
+		t.Errorf("Expected NAError at row 1, but got %v", err)
+	}
+
+	// Test case 2: only rows with missing values in the selected columns are dropped.
+	ds, err := table.Dataset([]string{"bill_length_mm"}, []string{"body_mass_g"}, NADrop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds.Inputs) != 3 || len(ds.Dropped) != 1 || ds.Dropped[0] != 1 {
+		t.Errorf("Expected 3 rows and row 1 dropped, but got %d rows and %v dropped", len(ds.Inputs), ds.Dropped)
+	}
+	if ds.Inputs[2][0] != 46.5 || ds.Targets[2][0] != 3500 {
+		t.Errorf("Expected [46.5] -> [3500], but got %v -> %v", ds.Inputs[2], ds.Targets[2])
+	}
+
+	// Test case 3: unknown columns are reported.
+	if _, err := table.Dataset([]string{"flipper_length_mm"}, nil, NADrop); err == nil {
+		t.Errorf("Expected error for unknown column")
+	}
+}
@@ -0,0 +1,91 @@
+package dataset
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// SplitOptions configures how rows are partitioned by NewSplit.
+type SplitOptions struct {
+	Validation float64  // Fraction of the rows held out for validation.
+	Test       float64  // Fraction of the rows held out for testing.
+	Seed       int64    // Seed of the shuffle, so that the same options always give the same split.
+	Stratify   []string // Optional class label of every row. When set, each class is split with the same fractions.
+}
+
+// Split holds the row indices of each partition of a data set.
+type Split struct {
+	Train      []int
+	Validation []int
+	Test       []int
+}
+
+// NewSplit shuffles the indices 0..n-1 and partitions them into train, validation and test sets.
+func NewSplit(n int, opts SplitOptions) (Split, error) {
+	if opts.Validation < 0 || opts.Test < 0 || opts.Validation+opts.Test >= 1 {
+		return Split{}, fmt.Errorf("dataset: invalid split fractions %v and %v", opts.Validation, opts.Test)
+	}
+	if opts.Stratify != nil && len(opts.Stratify) != n {
+		return Split{}, fmt.Errorf("dataset: %d stratification labels for %d rows", len(opts.Stratify), n)
+	}
+
+	// Group the rows by class, in a fixed order of classes so that the result only depends on the seed.
+	groups := map[string][]int{}
+	for i := 0; i < n; i++ {
+		label := ""
+		if opts.Stratify != nil {
+			label = opts.Stratify[i]
+		}
+		groups[label] = append(groups[label], i)
+	}
+	labels := make([]string, 0, len(groups))
+	for label := range groups {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	var s Split
+	for _, label := range labels {
+		rows := groups[label]
+		rng.Shuffle(len(rows), func(i, j int) { rows[i], rows[j] = rows[j], rows[i] })
+		numTest := int(math.Round(opts.Test * float64(len(rows))))
+		numVal := int(math.Round(opts.Validation * float64(len(rows))))
+		if numTest+numVal > len(rows) {
+			numVal = len(rows) - numTest
+		}
+		s.Test = append(s.Test, rows[:numTest]...)
+		s.Validation = append(s.Validation, rows[numTest:numTest+numVal]...)
+		s.Train = append(s.Train, rows[numTest+numVal:]...)
+	}
+
+	// Mix the classes again inside each partition.
+	for _, part := range [][]int{s.Train, s.Validation, s.Test} {
+		rng.Shuffle(len(part), func(i, j int) { part[i], part[j] = part[j], part[i] })
+	}
+	return s, nil
+}
+
+// Take returns the rows of m at the given indices. The rows are shared, not copied.
+func Take(m [][]float64, indices []int) [][]float64 {
+	result := make([][]float64, len(indices))
+	for i, idx := range indices {
+		result[i] = m[idx]
+	}
+	return result
+}
+
+// Split materializes the partitions of s as three data sets.
+func (d *Dataset) Split(s Split) (train, validation, test *Dataset) {
+	subset := func(indices []int) *Dataset {
+		return &Dataset{
+			Inputs:      Take(d.Inputs, indices),
+			Targets:     Take(d.Targets, indices),
+			InputNames:  d.InputNames,
+			TargetNames: d.TargetNames,
+		}
+	}
+	return subset(s.Train), subset(s.Validation), subset(s.Test)
+}
@@ -0,0 +1,52 @@
+package dataset
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestNewSplit(t *testing.T) {
+	var labels []string
+	for i := 0; i < 40; i++ {
+		labels = append(labels, "Adelie")
+	}
+	for i := 0; i < 10; i++ {
+		labels = append(labels, "Chinstrap")
+	}
+	opts := SplitOptions{Validation: 0.2, Test: 0.2, Seed: 42, Stratify: labels}
+	s, err := NewSplit(len(labels), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every class is represented in every partition with the requested fractions.
+	for name, part := range map[string][]int{"train": s.Train, "validation": s.Validation, "test": s.Test} {
+		counts := map[string]int{}
+		for _, i := range part {
+			counts[labels[i]]++
+		}
+		expected := map[string]int{"Adelie": 8, "Chinstrap": 2}
+		if name == "train" {
+			expected = map[string]int{"Adelie": 24, "Chinstrap": 6}
+		}
+		if !reflect.DeepEqual(counts, expected) {
+			t.Errorf("Expected %s counts %v, but got %v", name, expected, counts)
+		}
+	}
+
+	// The partitions cover every row exactly once.
+	all := append(append(append([]int{}, s.Train...), s.Validation...), s.Test...)
+	sort.Ints(all)
+	for i := range all {
+		if all[i] != i {
+			t.Fatalf("Expected a permutation of the rows, but got %v", all)
+		}
+	}
+
+	// The same seed gives the same split.
+	again, _ := NewSplit(len(labels), opts)
+	if !reflect.DeepEqual(s, again) {
+		t.Errorf("Expected identical splits for the same seed")
+	}
+}
@@ -1,56 +1,236 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
 
+	"github.com/mreza101/gonn/ch3/dataset"
 	"github.com/mreza101/gonn/ch3/models"
 	"github.com/mreza101/gonn/ch3/nn"
+	"github.com/mreza101/gonn/ch3/preprocess"
 )
 
-type data struct {
-	Input   []float64
-	Targets []float64
-}
+// features are the numeric columns of the penguins data set used to predict the species.
+var features = []string{"bill_length_mm", "bill_depth_mm", "flipper_length_mm", "body_mass_g"}
 
-// trainingData for a logical OR
-var trainingData = []data{
-	{Input: []float64{0, 0}, Targets: []float64{0}},
-	{Input: []float64{0, 1}, Targets: []float64{1}},
-	{Input: []float64{1, 0}, Targets: []float64{1}},
-	{Input: []float64{1, 1}, Targets: []float64{1}},
+var dataPath = flag.String("data", "../datasets/penguins.csv", "Path of the penguins CSV file")
+var hidden = flag.Int("hidden", 8, "Number of units of the hidden layer")
+var epochs = flag.Int("epochs", 10, "Number of epochs to train for")
+var learningRate = flag.Float64("learning-rate", 0.1, "Learning rate to use")
+var batchSize = flag.Int("batch-size", 1, "Number of samples per weight update, 0 for the whole training set")
+var seed = flag.Int64("seed", 1, "Seed used to initialize the network and to split and shuffle the data")
+var patience = flag.Int("patience", 0, "Stop after this many epochs without improvement of the validation loss, 0 to never stop early")
+var historyPath = flag.String("history", "", "If set, write the training history to this file, as JSON if it ends in .json and as CSV otherwise")
+var schedule = flag.String("schedule", "constant", "Learning rate schedule: constant, step, exponential, cosine or plateau")
+var decay = flag.Float64("decay", 0.5, "Factor by which the step, exponential and plateau schedules multiply the learning rate")
+var decayEvery = flag.Int("decay-every", 10, "Epochs between decays of the step schedule, length of the first cycle of the cosine schedule and patience of the plateau schedule")
+var warmup = flag.Int("warmup", 0, "Number of updates over which the learning rate ramps up linearly")
+var clipValue = flag.Float64("clip-value", 0, "Clip every gradient element to this magnitude, 0 to disable")
+var clipNorm = flag.Float64("clip-norm", 0, "Clip the global L2 norm of the gradients to this value, 0 to disable")
+var l2 = flag.Float64("l2", 0, "Factor of the L2 penalty on the weights of every layer")
+var dropout = flag.Float64("dropout", 0, "Probability of dropping each hidden unit during training")
+var modelPath = flag.String("save", "", "If set, save the trained network to this file, as JSON if it ends in .json and in binary otherwise")
+var checkpointPath = flag.String("checkpoint", "", "If set, save the state of training to this file every epoch, and resume from it if it exists")
+var optimizer = flag.String("optimizer", "sgd", "Optimizer to use: sgd, momentum, nesterov, adagrad, rmsprop, adam or adamw")
+
+// newOptimizer creates the optimizer selected on the command line.
+func newOptimizer(name string) (nn.Optimizer, error) {
+	switch name {
+	case "sgd":
+		return nn.SGD{}, nil
+	case "momentum":
+		return nn.NewMomentum(0.9), nil
+	case "nesterov":
+		return nn.NewNesterov(0.9), nil
+	case "adagrad":
+		return nn.NewAdagrad(), nil
+	case "rmsprop":
+		return nn.NewRMSProp(0.9), nil
+	case "adam":
+		return nn.NewAdam(), nil
+	case "adamw":
+		return nn.NewAdamW(0.01), nil
+	}
+	return nil, fmt.Errorf("unknown optimizer %q", name)
 }
 
-func getInputs(all []data) [][]float64 {
-	var inputs [][]float64
-	for _, d := range all {
-		inputs = append(inputs, d.Input)
+// newSchedule creates the learning rate schedule selected on the command line.
+func newSchedule(name string) (nn.Schedule, error) {
+	var s nn.Schedule
+	if (name == "step" || name == "cosine" || name == "plateau") && *decayEvery < 1 {
+		return nil, fmt.Errorf("-decay-every must be at least 1, got %d", *decayEvery)
 	}
-	return inputs
+	switch name {
+	case "constant":
+		s = nn.ConstantRate(*learningRate)
+	case "step":
+		s = nn.StepDecay{Initial: *learningRate, Factor: *decay, Every: *decayEvery}
+	case "exponential":
+		s = nn.ExponentialDecay{Initial: *learningRate, Factor: *decay}
+	case "cosine":
+		s = nn.CosineRestarts{Max: *learningRate, Period: *decayEvery, Mult: 2}
+	case "plateau":
+		s = nn.NewReduceOnPlateau(*learningRate, *decay, *decayEvery)
+	default:
+		return nil, fmt.Errorf("unknown schedule %q", name)
+	}
+	if *warmup > 0 {
+		s = nn.Warmup{Steps: *warmup, Schedule: s}
+	}
+	return s, nil
 }
 
-func getTargets(all []data) [][]float64 {
-	var targets [][]float64
-	for _, d := range all {
-		targets = append(targets, d.Targets)
+// loadPenguins reads the data set, keeping the rows that have all the features and the species.
+// The inputs are the features and the targets the one-hot encoded species.
+func loadPenguins(path string) (*dataset.Dataset, *preprocess.OneHotEncoder, error) {
+	table, err := dataset.LoadCSV(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	table, _, err = table.DropNA(append([]string{"species"}, features...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	ds, err := table.Dataset(features, nil, dataset.NAFail)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	species, err := table.Column("species")
+	if err != nil {
+		return nil, nil, err
+	}
+	encoder := preprocess.NewOneHotEncoder(preprocess.UnknownError)
+	if ds.Targets, err = encoder.FitTransform(species); err != nil {
+		return nil, nil, err
 	}
-	return targets
+	ds.TargetNames = encoder.Categories
+	return ds, encoder, nil
 }
 
-var epochs = flag.Int("epochs", 10, "Number of epochs to train for")
-var learningRate = flag.Float64("learning-rate", 0.1, "Learning rate to use")
+// writeHistory saves the training history as JSON or CSV depending on the extension of path.
+func writeHistory(path string, history *nn.History) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(path, ".json") {
+		err = history.WriteJSON(f)
+	} else {
+		err = history.WriteCSV(f)
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
 
 func main() {
-	// Create a new perceptron.
-	model := models.NewNetwork()
-	model.AddLayer(2, 1, nn.ASigmoid)
-
-	// Train the perceptron.
-	model.TrainAll(getInputs(trainingData), getTargets(trainingData), *epochs, *learningRate)
-
-	// Test the perceptron.
-	fmt.Printf("0,0 = %f\n", model.FeedForward([]float64{0, 0}))
-	fmt.Printf("0,1 = %f\n", model.FeedForward([]float64{0, 1}))
-	fmt.Printf("1,0 = %f\n", model.FeedForward([]float64{1, 0}))
-	fmt.Printf("1,1 = %f\n", model.FeedForward([]float64{1, 1}))
+	flag.Parse()
+	opt, err := newOptimizer(*optimizer)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sched, err := newSchedule(*schedule)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Load the data and hold out stratified validation and test sets.
+	ds, encoder, err := loadPenguins(*dataPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	split, err := dataset.NewSplit(len(ds.Inputs), dataset.SplitOptions{
+		Validation: 0.2,
+		Test:       0.2,
+		Seed:       *seed,
+		Stratify:   encoder.InverseTransform(ds.Targets),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	train, validation, test := ds.Split(split)
+
+	// Scale the features with statistics of the training set only.
+	scaler := &preprocess.StandardScaler{}
+	scaler.Fit(train.Inputs)
+	train.Inputs = scaler.Transform(train.Inputs)
+	validation.Inputs = scaler.Transform(validation.Inputs)
+	test.Inputs = scaler.Transform(test.Inputs)
+
+	// Create a network with one hidden layer and a softmax output layer.
+	layerOpts := []nn.LayerOption{nn.WithInitializer(nn.GlorotUniform), nn.WithBiasInitializer(nn.Zeros), nn.WithRegularizer(nn.Regularizer{L2: *l2})}
+	builder := models.NewSequential(len(features)).Seed(*seed).Dense(*hidden, nn.ATanh, layerOpts...)
+	if *dropout > 0 {
+		builder.Dropout(*dropout)
+	}
+	model, err := builder.Dense(len(encoder.Categories), nn.ASoftmax, layerOpts...).Build()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Train the network.
+	var earlyStopping *nn.EarlyStopping
+	if *patience > 0 {
+		earlyStopping = &nn.EarlyStopping{Patience: *patience, RestoreBest: true}
+	}
+	// Stop training gracefully on Ctrl-C: the network keeps the weights of the last batch and is still tested.
+	// A second Ctrl-C kills the program as usual.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	opts := nn.TrainOptions{
+		Epochs:    *epochs,
+		BatchSize: *batchSize,
+		Schedule:  sched,
+		Optimizer: opt,
+		Loss:      nn.CategoricalCrossEntropy{},
+		Shuffle:   true,
+		Seed:      *seed,
+		ClipValue: *clipValue,
+		ClipNorm:  *clipNorm,
+		Log:       os.Stdout,
+
+		ValInputs:     validation.Inputs,
+		ValTargets:    validation.Targets,
+		EarlyStopping: earlyStopping,
+
+		CheckpointPath: *checkpointPath,
+	}
+	// With -checkpoint, continue the previous run if it left a checkpoint.
+	var history *nn.History
+	err = os.ErrNotExist
+	if *checkpointPath != "" {
+		history, err = model.Resume(ctx, *checkpointPath, train.Inputs, train.Targets, opts)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		history, err = model.FitContext(ctx, train.Inputs, train.Targets, opts)
+	}
+	stop()
+	if errors.Is(err, context.Canceled) {
+		fmt.Println("training interrupted")
+	} else if err != nil {
+		log.Fatal(err)
+	}
+
+	if *modelPath != "" {
+		if err := model.Save(*modelPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *historyPath != "" {
+		if err := writeHistory(*historyPath, history); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Test the network.
+	fmt.Printf("train accuracy = %f\n", model.Test(train.Inputs, train.Targets))
+	fmt.Printf("test accuracy = %f\n", model.Test(test.Inputs, test.Targets))
 }
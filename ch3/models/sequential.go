@@ -0,0 +1,142 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/mreza101/gonn/ch3/nn"
+)
+
+// Sequential builds a network from the input layer to the output layer. Only the number of inputs of
+// the network is given up front: every layer takes as many inputs as the previous layer has outputs.
+//
+//	net, err := models.NewSequential(4).
+//		Dense(8, nn.ATanh).
+//		Dropout(0.2).
+//		Dense(3, nn.ASoftmax).
+//		Build()
+//
+// Methods record the layers and return the builder, so that calls can be chained. Mistakes are
+// reported by Build, which returns the first of them.
+type Sequential struct {
+	numInputs int
+	seed      *int64
+	layers    []sequentialLayer
+}
+
+// sequentialLayer is a layer recorded by a Sequential builder.
+type sequentialLayer struct {
+	name       string
+	numInputs  int                           // Number of inputs the layer requires, or -1 if it takes any number.
+	numOutputs int                           // Number of outputs of the layer, or -1 if it has as many as inputs.
+	add        func(n *nn.Network, size int) // Adds the layer to n, given its number of inputs.
+	err        error
+}
+
+// NewSequential starts building a network with numInputs inputs.
+func NewSequential(numInputs int) *Sequential {
+	return &Sequential{numInputs: numInputs}
+}
+
+// Seed sets the seed of the network, as in nn.Network.SetSeed, so that it is initialized the same way every time.
+func (s *Sequential) Seed(seed int64) *Sequential {
+	s.seed = &seed
+	return s
+}
+
+// Dense adds a dense layer with numOutputs units and activation act. opts configure the layer as in nn.NewDense.
+func (s *Sequential) Dense(numOutputs int, act nn.ActivationType, opts ...nn.LayerOption) *Sequential {
+	l := sequentialLayer{name: "dense", numInputs: -1, numOutputs: numOutputs, add: func(n *nn.Network, size int) {
+		n.AddLayer(size, numOutputs, act, opts...)
+	}}
+	if numOutputs < 1 {
+		l.err = fmt.Errorf("dense layer must have at least one output, got %d", numOutputs)
+	}
+	return s.append(l)
+}
+
+// Activation adds an activation layer.
+func (s *Sequential) Activation(act nn.ActivationType) *Sequential {
+	return s.append(sequentialLayer{name: "activation", numInputs: -1, numOutputs: -1, add: func(n *nn.Network, size int) {
+		n.AddActivation(act)
+	}})
+}
+
+// Dropout adds a dropout layer dropping its inputs with probability rate during training.
+func (s *Sequential) Dropout(rate float64) *Sequential {
+	l := sequentialLayer{name: "dropout", numInputs: -1, numOutputs: -1, add: func(n *nn.Network, size int) {
+		n.AddDropout(rate)
+	}}
+	if rate < 0 || rate >= 1 {
+		l.err = fmt.Errorf("dropout rate must be in [0, 1), got %g", rate)
+	}
+	return s.append(l)
+}
+
+// BatchNorm adds a batch normalization layer.
+func (s *Sequential) BatchNorm() *Sequential {
+	return s.append(sequentialLayer{name: "batch normalization", numInputs: -1, numOutputs: -1, add: func(n *nn.Network, size int) {
+		n.AddBatchNorm(size)
+	}})
+}
+
+// LayerNorm adds a layer normalization layer.
+func (s *Sequential) LayerNorm() *Sequential {
+	return s.append(sequentialLayer{name: "layer normalization", numInputs: -1, numOutputs: -1, add: func(n *nn.Network, size int) {
+		n.AddLayerNorm(size)
+	}})
+}
+
+// Add adds a layer built by the caller, such as a user-defined nn.Layer. Since its sizes cannot be inferred,
+// they are given: Build fails if numInputs differs from the number of outputs of the previous layer,
+// or from the sizes of the layer itself if it implements nn.Sized.
+func (s *Sequential) Add(layer nn.Layer, numInputs, numOutputs int) *Sequential {
+	l := sequentialLayer{name: fmt.Sprintf("%T", layer), numInputs: numInputs, numOutputs: numOutputs, add: func(n *nn.Network, size int) {
+		n.Add(layer)
+	}}
+	if sized, ok := layer.(nn.Sized); ok && (sized.NumInputs() != numInputs || sized.NumOutputs() != numOutputs) {
+		l.err = fmt.Errorf("layer has %d inputs and %d outputs, but %d and %d were given", sized.NumInputs(), sized.NumOutputs(), numInputs, numOutputs)
+	}
+	return s.append(l)
+}
+
+func (s *Sequential) append(l sequentialLayer) *Sequential {
+	s.layers = append(s.layers, l)
+	return s
+}
+
+// Build checks that the sizes of consecutive layers match and creates the network.
+// Layers are counted from 1, the layer after the inputs, in errors.
+func (s *Sequential) Build() (*nn.Network, error) {
+	if len(s.layers) == 0 {
+		return nil, fmt.Errorf("models: sequential network has no layers")
+	}
+	if s.numInputs < 1 {
+		return nil, fmt.Errorf("models: sequential network must have at least one input, got %d", s.numInputs)
+	}
+
+	// Infer the number of inputs of every layer, from the input layer to the output layer.
+	sizes := make([]int, len(s.layers))
+	size := s.numInputs
+	for i, l := range s.layers {
+		if l.err != nil {
+			return nil, fmt.Errorf("models: layer %d (%s): %w", i+1, l.name, l.err)
+		}
+		if l.numInputs >= 0 && l.numInputs != size {
+			return nil, fmt.Errorf("models: layer %d (%s) takes %d inputs, but the previous layer has %d outputs", i+1, l.name, l.numInputs, size)
+		}
+		sizes[i] = size
+		if l.numOutputs >= 0 {
+			size = l.numOutputs
+		}
+	}
+
+	// nn.Network takes its layers from the output layer to the input layer.
+	n := NewNetwork()
+	if s.seed != nil {
+		n.SetSeed(*s.seed)
+	}
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		s.layers[i].add(n, sizes[i])
+	}
+	return n, nil
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mreza101/gonn/ch3/nn"
+)
+
+func TestSequential_Build(t *testing.T) {
+	net, err := NewSequential(2).
+		Seed(1).
+		Dense(4, nn.ATanh).
+		LayerNorm().
+		Dropout(0.5).
+		Dense(3, nn.ASoftmax).
+		Build()
+	if err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if outputs := net.FeedForward([]float64{0.5, -1}); len(outputs) != 3 {
+		t.Errorf("Expected 3 outputs, but got %v", outputs)
+	}
+
+	// The same network built output first with AddLayer.
+	manual := nn.NewNetwork()
+	manual.SetSeed(1)
+	manual.AddLayer(4, 3, nn.ASoftmax)
+	manual.AddDropout(0.5)
+	manual.AddLayerNorm(4)
+	manual.AddLayer(2, 4, nn.ATanh)
+	if a, b := net.FeedForward([]float64{0.5, -1}), manual.FeedForward([]float64{0.5, -1}); a[0] != b[0] {
+		t.Errorf("Expected the same outputs as AddLayer, but got %v and %v", a, b)
+	}
+}
+
+func TestSequential_BuildErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *Sequential
+		err     string
+	}{
+		{"no layers", NewSequential(2), "no layers"},
+		{"no outputs", NewSequential(2).Dense(0, nn.ASigmoid), "layer 1 (dense)"},
+		{"mismatch", NewSequential(2).Dense(4, nn.ATanh).Add(nn.NewDense(3, 1, nn.ASigmoid), 3, 1), "layer 2 (*nn.Dense) takes 3 inputs, but the previous layer has 4 outputs"},
+		{"sized", NewSequential(2).Dense(4, nn.ATanh).Add(nn.NewDense(5, 1, nn.ASigmoid), 4, 1), "layer 2 (*nn.Dense): layer has 5 inputs and 1 outputs, but 4 and 1 were given"},
+		{"dropout rate", NewSequential(2).Dropout(1).Dense(1, nn.ASigmoid), "dropout rate"},
+	}
+	for _, tt := range tests {
+		_, err := tt.builder.Build()
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected an error containing %q, but got %v", tt.name, tt.err, err)
+		}
+	}
+}
@@ -11,11 +11,14 @@ const (
 	ASigmoid
 	AReLU
 	ATanh
+	ASoftmax // Not element-wise: the outputs of the whole layer form a probability distribution.
 )
 
 // ActivationFunction is the type of the different activation functions.
 type ActivationFunction func(float64) float64
 
+// ActivationFuncs and ActivationPrimes only contain the element-wise activation functions.
+
 var (
 	ActivationFuncs = map[ActivationType]ActivationFunction{
 		AStep:    Step,
@@ -82,3 +85,98 @@ func Tanh(x float64) float64 {
 func TanhPrime(x float64) float64 {
 	return 1 - math.Pow(Tanh(x), 2)
 }
+
+// Softmax turns a vector of scores into a probability distribution.
+// The largest score is subtracted first so that large scores do not overflow.
+func Softmax(xs []float64) []float64 {
+	largest := xs[0]
+	for _, x := range xs {
+		largest = math.Max(largest, x)
+	}
+	result := make([]float64, len(xs))
+	sum := 0.0
+	for i, x := range xs {
+		result[i] = math.Exp(x - largest)
+		sum += result[i]
+	}
+	for i := range result {
+		result[i] /= sum
+	}
+	return result
+}
+
+// activate applies the activation function act to the weighted sums of a layer.
+func activate(act ActivationType, sums []float64) []float64 {
+	if act == ASoftmax {
+		return Softmax(sums)
+	}
+	acFunc := ActivationFuncs[act]
+	outputs := make([]float64, len(sums))
+	for i := range sums {
+		outputs[i] = acFunc(sums[i])
+	}
+	return outputs
+}
+
+// activationDeltas calculates the derivative of the loss with respect to the weighted sums of a layer
+// with activation function act, given its derivative with respect to the outputs.
+func activationDeltas(act ActivationType, sums, outputs, outputGrads []float64) []float64 {
+	deltas := make([]float64, len(sums))
+	if act == ASoftmax {
+		// Every output of a softmax depends on every sum: dy[i]/dz[j] = y[i] * ([i == j] - y[j]).
+		mean := dot(outputGrads, outputs)
+		for j := range deltas {
+			deltas[j] = outputs[j] * (outputGrads[j] - mean)
+		}
+		return deltas
+	}
+	acPrime := ActivationPrimes[act]
+	for j := range deltas {
+		deltas[j] = outputGrads[j] * acPrime(sums[j])
+	}
+	return deltas
+}
+
+// Activation is a layer that applies an activation function to each of its inputs, or a softmax to all of them.
+// It has no parameters. It lets a network apply an activation after a normalization layer, for instance.
+type Activation struct {
+	Type ActivationType
+
+	inputs, outputs [][]float64 // Inputs and outputs of the last forward pass in training mode, kept for backward.
+}
+
+// NewActivation creates an activation layer applying act.
+func NewActivation(act ActivationType) *Activation {
+	return &Activation{Type: act}
+}
+
+// Forward implements Layer.
+func (a *Activation) Forward(inputs [][]float64, training bool) [][]float64 {
+	outputs := make([][]float64, len(inputs))
+	for t := range inputs {
+		outputs[t] = activate(a.Type, inputs[t])
+	}
+	if training {
+		a.inputs, a.outputs = inputs, outputs
+	}
+	return outputs
+}
+
+// Backward implements Layer.
+func (a *Activation) Backward(outputGrads [][]float64) [][]float64 {
+	inputGrads := make([][]float64, len(outputGrads))
+	for t := range outputGrads {
+		inputGrads[t] = activationDeltas(a.Type, a.inputs[t], a.outputs[t], outputGrads[t])
+	}
+	return inputGrads
+}
+
+// Params implements Layer.
+func (a *Activation) Params() []float64 {
+	return nil
+}
+
+// Grads implements Layer.
+func (a *Activation) Grads() []float64 {
+	return nil
+}
@@ -0,0 +1,152 @@
+package nn
+
+import "math"
+
+// BatchNorm is a layer that normalizes each of its inputs to zero mean and unit variance, then scales and shifts it
+// by a learned gain and bias. In training mode the mean and variance are those of the current batch, and running
+// averages of them are kept. In inference mode the running averages are used instead, so the output of a sample
+// does not depend on the other samples of its batch.
+//
+// Batch statistics need batches of several samples: train networks with batch normalization with Fit and a BatchSize
+// larger than 1, not with Train. Batches of a single sample leave the running averages unchanged.
+type BatchNorm struct {
+	Momentum float64 // Weight of the past in the running averages, such as 0.9.
+	Epsilon  float64 // Added to the variances to avoid dividing by 0.
+
+	RunningMean []float64 // State: running average of the batch means, used in inference mode.
+	RunningVar  []float64 // State: running average of the batch variances, used in inference mode.
+
+	gamma, beta []float64 // Gain and bias of every input, views of params.
+
+	params     []float64 // Storage of gamma followed by beta.
+	paramGrads []float64 // Storage of the gradients of gamma and beta.
+	stats      []float64 // Storage of RunningMean followed by RunningVar.
+
+	normalized [][]float64 // Normalized inputs of the last forward pass in training mode, kept for backward.
+	invStd     []float64   // 1/sqrt(variance + Epsilon) of every input in the last forward pass in training mode.
+}
+
+// NewBatchNorm creates a batch normalization layer for size inputs, with a momentum of 0.9.
+// The gains start at 1 and the biases at 0, so that the layer starts as a plain normalization.
+func NewBatchNorm(size int) *BatchNorm {
+	params := make([]float64, 2*size)
+	for i := 0; i < size; i++ {
+		params[i] = 1
+	}
+	stats := make([]float64, 2*size)
+	for i := size; i < 2*size; i++ {
+		stats[i] = 1
+	}
+	return &BatchNorm{
+		Momentum:    0.9,
+		Epsilon:     1e-5,
+		RunningMean: stats[:size],
+		RunningVar:  stats[size:],
+		gamma:       params[:size],
+		beta:        params[size:],
+		params:      params,
+		paramGrads:  make([]float64, 2*size),
+		stats:       stats,
+	}
+}
+
+// NumInputs implements Sized.
+func (b *BatchNorm) NumInputs() int {
+	return len(b.gamma)
+}
+
+// NumOutputs implements Sized.
+func (b *BatchNorm) NumOutputs() int {
+	return len(b.gamma)
+}
+
+// Forward implements Layer.
+func (b *BatchNorm) Forward(inputs [][]float64, training bool) [][]float64 {
+	size := len(b.gamma)
+	mean, variance := b.RunningMean, b.RunningVar
+	if training {
+		mean, variance = make([]float64, size), make([]float64, size)
+		n := float64(len(inputs))
+		for t := range inputs {
+			for i := range mean {
+				mean[i] += inputs[t][i] / n
+			}
+		}
+		for t := range inputs {
+			for i := range variance {
+				d := inputs[t][i] - mean[i]
+				variance[i] += d * d / n
+			}
+		}
+		// A single sample has a variance of 0, which says nothing of the variance of the data: it does not
+		// update the running averages. Fit makes such batches when the last batch of an epoch has one sample.
+		if len(inputs) > 1 {
+			for i := range mean {
+				b.RunningMean[i] = b.Momentum*b.RunningMean[i] + (1-b.Momentum)*mean[i]
+				b.RunningVar[i] = b.Momentum*b.RunningVar[i] + (1-b.Momentum)*variance[i]
+			}
+		}
+	}
+
+	invStd := make([]float64, size)
+	for i := range invStd {
+		invStd[i] = 1 / math.Sqrt(variance[i]+b.Epsilon)
+	}
+	normalized := make([][]float64, len(inputs))
+	outputs := make([][]float64, len(inputs))
+	for t := range inputs {
+		normalized[t] = make([]float64, size)
+		outputs[t] = make([]float64, size)
+		for i := range normalized[t] {
+			normalized[t][i] = (inputs[t][i] - mean[i]) * invStd[i]
+			outputs[t][i] = b.gamma[i]*normalized[t][i] + b.beta[i]
+		}
+	}
+	if training {
+		b.normalized, b.invStd = normalized, invStd
+	}
+	return outputs
+}
+
+// Backward implements Layer.
+func (b *BatchNorm) Backward(outputGrads [][]float64) [][]float64 {
+	size := len(b.gamma)
+	gammaGrads, betaGrads := b.paramGrads[:size], b.paramGrads[size:]
+	// The mean and variance depend on every sample of the batch, so the derivative with respect to an input
+	// gathers the derivatives of all samples: dx = invStd/n * (n*dxhat - sum(dxhat) - xhat*sum(dxhat*xhat)).
+	sumGrads, sumProducts := make([]float64, size), make([]float64, size)
+	for t := range outputGrads {
+		for i := range outputGrads[t] {
+			gammaGrads[i] += outputGrads[t][i] * b.normalized[t][i]
+			betaGrads[i] += outputGrads[t][i]
+			dxhat := outputGrads[t][i] * b.gamma[i]
+			sumGrads[i] += dxhat
+			sumProducts[i] += dxhat * b.normalized[t][i]
+		}
+	}
+	n := float64(len(outputGrads))
+	inputGrads := make([][]float64, len(outputGrads))
+	for t := range outputGrads {
+		inputGrads[t] = make([]float64, size)
+		for i := range inputGrads[t] {
+			dxhat := outputGrads[t][i] * b.gamma[i]
+			inputGrads[t][i] = b.invStd[i] / n * (n*dxhat - sumGrads[i] - b.normalized[t][i]*sumProducts[i])
+		}
+	}
+	return inputGrads
+}
+
+// Params implements Layer.
+func (b *BatchNorm) Params() []float64 {
+	return b.params
+}
+
+// Grads implements Layer.
+func (b *BatchNorm) Grads() []float64 {
+	return b.paramGrads
+}
+
+// State implements Stateful.
+func (b *BatchNorm) State() []float64 {
+	return b.stats
+}
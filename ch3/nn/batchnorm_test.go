@@ -0,0 +1,50 @@
+package nn
+
+import "testing"
+
+func TestBatchNorm_Backprop(t *testing.T) {
+	net := NewNetwork()
+	net.SetSeed(1)
+	net.AddLayer(3, 2, ASigmoid, WithInitializer(GlorotUniform))
+	net.AddBatchNorm(3)
+	net.AddLayer(2, 3, ATanh, WithInitializer(GlorotUniform))
+	inputs := [][]float64{{0.5, -1}, {1, 0.2}, {-0.3, 0.8}, {0, 0}}
+	targets := [][]float64{{1, 0}, {0, 1}, {1, 1}, {0, 0}}
+	lossOf := func() float64 {
+		total := 0.0
+		for t, outputs := range net.forward(inputs, true) {
+			total += MSE{}.Value(outputs, targets[t])
+		}
+		return total
+	}
+
+	net.backprop(inputs, targets, MSE{})
+
+	// Compare every gradient against a central finite difference of the loss of the whole batch.
+	checkGradients(t, net, lossOf)
+}
+
+func TestBatchNorm_RunningStatistics(t *testing.T) {
+	b := NewBatchNorm(1)
+	b.Momentum, b.Epsilon = 0, 0
+	inputs := [][]float64{{1}, {3}}
+
+	outputs := b.Forward(inputs, true)
+	if !approxEqual(outputs[0][0], -1) || !approxEqual(outputs[1][0], 1) {
+		t.Errorf("Expected the batch normalized to [-1 1], but got %v", outputs)
+	}
+	if b.RunningMean[0] != 2 || b.RunningVar[0] != 1 {
+		t.Errorf("Expected running mean 2 and variance 1, but got %f and %f", b.RunningMean[0], b.RunningVar[0])
+	}
+
+	// A training batch of a single sample has no variance, so it leaves the running statistics alone.
+	b.Forward([][]float64{{10}}, true)
+	if b.RunningMean[0] != 2 || b.RunningVar[0] != 1 {
+		t.Errorf("Expected a single sample to keep running mean 2 and variance 1, but got %f and %f", b.RunningMean[0], b.RunningVar[0])
+	}
+
+	// In inference mode a single sample is normalized with the running statistics.
+	if output := b.Forward([][]float64{{3}}, false)[0][0]; !approxEqual(output, 1) {
+		t.Errorf("Expected 1 in inference mode, but got %f", output)
+	}
+}
@@ -0,0 +1,108 @@
+package nn
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Progress describes the state of a training run. Network.Fit passes the same Progress to every callback.
+type Progress struct {
+	Network      *Network           // Network being trained.
+	Epochs       int                // Number of epochs requested.
+	Epoch        int                // Current epoch, counted from 0.
+	Batch        int                // Current batch of the epoch, counted from 0.
+	Step         int                // Number of updates made since the start of training.
+	Loss         float64            // Mean loss of the last batch in OnBatchEnd, of the whole epoch in OnEpochEnd and OnTrainEnd.
+	Metrics      map[string]float64 // Metrics of the last finished epoch, such as "loss", "val_loss" and, with clipping, the number of "clipped" updates.
+	LearningRate float64            // Learning rate of the last update. Without a Schedule, callbacks may change it for the next updates.
+	GradNorm     float64            // Global L2 norm of the gradients of the last update, before clipping.
+	Clipped      bool               // Whether the gradients of the last update were clipped.
+
+	stop bool
+}
+
+// Stop asks Network.Fit to stop training after the current batch. The current epoch still ends
+// with OnEpochEnd, unless none of its batches was trained, and OnTrainEnd is always called.
+func (p *Progress) Stop() {
+	p.stop = true
+}
+
+// Stopped reports whether a callback asked training to stop.
+func (p *Progress) Stopped() bool {
+	return p.stop
+}
+
+// Callback is notified of the progress of Network.Fit.
+type Callback interface {
+	OnTrainBegin(p *Progress)
+	OnTrainEnd(p *Progress)
+	OnEpochBegin(p *Progress)
+	OnEpochEnd(p *Progress)
+	OnBatchEnd(p *Progress)
+}
+
+// CallbackFuncs is a Callback made of optional functions. nil functions are skipped.
+type CallbackFuncs struct {
+	TrainBegin func(p *Progress)
+	TrainEnd   func(p *Progress)
+	EpochBegin func(p *Progress)
+	EpochEnd   func(p *Progress)
+	BatchEnd   func(p *Progress)
+}
+
+// OnTrainBegin implements Callback.
+func (c CallbackFuncs) OnTrainBegin(p *Progress) { call(c.TrainBegin, p) }
+
+// OnTrainEnd implements Callback.
+func (c CallbackFuncs) OnTrainEnd(p *Progress) { call(c.TrainEnd, p) }
+
+// OnEpochBegin implements Callback.
+func (c CallbackFuncs) OnEpochBegin(p *Progress) { call(c.EpochBegin, p) }
+
+// OnEpochEnd implements Callback.
+func (c CallbackFuncs) OnEpochEnd(p *Progress) { call(c.EpochEnd, p) }
+
+// OnBatchEnd implements Callback.
+func (c CallbackFuncs) OnBatchEnd(p *Progress) { call(c.BatchEnd, p) }
+
+func call(f func(p *Progress), p *Progress) {
+	if f != nil {
+		f(p)
+	}
+}
+
+// nopCallback implements Callback with methods that do nothing. Callbacks embed it to only implement the methods they need.
+type nopCallback struct{}
+
+func (nopCallback) OnTrainBegin(p *Progress) {}
+func (nopCallback) OnTrainEnd(p *Progress)   {}
+func (nopCallback) OnEpochBegin(p *Progress) {}
+func (nopCallback) OnEpochEnd(p *Progress)   {}
+func (nopCallback) OnBatchEnd(p *Progress)   {}
+
+// Logger is a Callback that writes the metrics of every epoch to W.
+type Logger struct {
+	nopCallback
+	W io.Writer
+}
+
+// OnEpochEnd implements Callback.
+func (l *Logger) OnEpochEnd(p *Progress) {
+	fmt.Fprintf(l.W, "epoch %d/%d: %s\n", p.Epoch+1, p.Epochs, formatMetrics(p.Metrics))
+}
+
+// formatMetrics formats metrics as "name value" pairs sorted by name.
+func formatMetrics(metrics map[string]float64) string {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s %.6f", name, metrics[name])
+	}
+	return strings.Join(parts, " ")
+}
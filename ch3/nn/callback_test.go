@@ -0,0 +1,46 @@
+package nn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFit_Callbacks(t *testing.T) {
+	inputs := [][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}}
+	targets := [][]float64{{0}, {1}, {1}, {1}}
+	net := NewNetwork()
+	net.AddLayer(2, 1, ASigmoid)
+
+	var events []string
+	var rates []float64
+	record := CallbackFuncs{
+		TrainBegin: func(p *Progress) { events = append(events, "train-begin") },
+		TrainEnd:   func(p *Progress) { events = append(events, "train-end") },
+		EpochBegin: func(p *Progress) {
+			events = append(events, "epoch-begin")
+			p.LearningRate /= 2
+		},
+		EpochEnd: func(p *Progress) { events = append(events, "epoch-end") },
+		BatchEnd: func(p *Progress) {
+			events = append(events, "batch-end")
+			rates = append(rates, p.LearningRate)
+			if p.Epoch == 1 && p.Batch == 0 {
+				p.Stop()
+			}
+		},
+	}
+	net.Fit(inputs, targets, TrainOptions{Epochs: 5, BatchSize: 2, LearningRate: 1, Callbacks: []Callback{record}})
+
+	expected := []string{
+		"train-begin",
+		"epoch-begin", "batch-end", "batch-end", "epoch-end",
+		"epoch-begin", "batch-end", "epoch-end",
+		"train-end",
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected events %v, but got %v", expected, events)
+	}
+	if !reflect.DeepEqual(rates, []float64{0.5, 0.5, 0.25}) {
+		t.Errorf("Expected learning rates [0.5 0.5 0.25], but got %v", rates)
+	}
+}
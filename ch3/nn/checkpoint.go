@@ -0,0 +1,259 @@
+package nn
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"math/rand"
+	"os"
+	"reflect"
+)
+
+// checkpointVersion is the version of the checkpoints written by Network.Fit.
+const checkpointVersion = 1
+
+func init() {
+	// Warmup holds its schedule in an interface, which gob can only decode into registered types.
+	gob.Register(ConstantRate(0))
+	gob.Register(StepDecay{})
+	gob.Register(ExponentialDecay{})
+	gob.Register(CosineRestarts{})
+	gob.Register(Warmup{})
+	gob.Register(&ReduceOnPlateau{})
+}
+
+// countingSource is a math/rand source that counts the values drawn from it. math/rand does not expose the state
+// of its sources, but a seed and a number of draws are enough to put a source back in the same state.
+type countingSource struct {
+	src   rand.Source64
+	draws uint64
+}
+
+func newCountingSource(seed int64) *countingSource {
+	return &countingSource{src: rand.NewSource(seed).(rand.Source64)}
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.draws++
+	return s.src.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.draws = 0
+}
+
+// restore puts the source in the state it was in after draws values were drawn from it since it was seeded with seed.
+func (s *countingSource) restore(seed int64, draws uint64) {
+	s.Seed(seed)
+	for s.draws < draws {
+		s.Int63()
+	}
+}
+
+// checkpoint is the state of a training run at the end of an epoch, as written to TrainOptions.CheckpointPath.
+// It holds everything that changes during training, so that the run can be resumed exactly.
+type checkpoint struct {
+	Version      int
+	Epoch        int         // Number of epochs completed.
+	Step         int         // Number of updates made.
+	LearningRate float64     // Learning rate of the last update.
+	Stopped      bool        // Whether a callback, such as EarlyStopping, stopped the run.
+	Seed         int64       // Seed of the source of randomness of the network.
+	Draws        uint64      // Number of values drawn from the source of randomness of the network since it was seeded.
+	Weights      [][]float64 // Parameters and state of every layer, as returned by Network.weights.
+	History      History     // History of the epochs completed.
+
+	OptimizerType string // Type of the optimizer, which must be the same when resuming.
+	Optimizer     []byte // gob encoding of the optimizer, including its moment buffers.
+	ScheduleType  string // Type of the schedule, which must be the same when resuming.
+	Schedule      []byte // gob encoding of the schedule.
+	EarlyStopping *earlyStoppingState
+}
+
+// earlyStoppingState is the state of an EarlyStopping, including its unexported fields.
+type earlyStoppingState struct {
+	BestEpoch    int
+	Best         float64
+	StoppedEpoch int
+	Wait         int
+	BestWeights  [][]float64
+}
+
+// newCheckpoint records the state of a training run of n with optimizer opt, at the end of the epoch p.Epoch.
+func (n *Network) newCheckpoint(p *Progress, opt Optimizer, opts *TrainOptions, history *History) (*checkpoint, error) {
+	c := &checkpoint{
+		Version:      checkpointVersion,
+		Epoch:        p.Epoch + 1,
+		Step:         p.Step,
+		LearningRate: p.LearningRate,
+		Stopped:      p.stop,
+		Seed:         n.seed,
+		Draws:        n.source.draws,
+		Weights:      n.weights(),
+		History:      *history,
+	}
+	var err error
+	if c.OptimizerType, c.Optimizer, err = encodeState(opt); err != nil {
+		return nil, err
+	}
+	if c.ScheduleType, c.Schedule, err = encodeState(opts.Schedule); err != nil {
+		return nil, err
+	}
+	if e := opts.EarlyStopping; e != nil {
+		c.EarlyStopping = &earlyStoppingState{BestEpoch: e.BestEpoch, Best: e.Best, StoppedEpoch: e.StoppedEpoch, Wait: e.wait, BestWeights: e.bestWeights}
+	}
+	return c, nil
+}
+
+// save writes c to the file at path. It writes a temporary file first, so that a run interrupted
+// while saving leaves the previous checkpoint intact.
+func (c *checkpoint) save(path string) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(c); err != nil {
+		return fmt.Errorf("nn: checkpoint: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// loadCheckpoint reads a checkpoint written by save.
+func loadCheckpoint(path string) (*checkpoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var c checkpoint
+	if err := gob.NewDecoder(f).Decode(&c); err != nil {
+		return nil, fmt.Errorf("nn: checkpoint: %w", err)
+	}
+	if c.Version < 1 || c.Version > checkpointVersion {
+		return nil, &VersionError{What: "checkpoint", Version: c.Version, Max: checkpointVersion}
+	}
+	return &c, nil
+}
+
+// restore puts n, the optimizer and the schedule of opts and p back in the state recorded in c.
+// The EarlyStopping of opts is restored by restoreEarlyStopping, after OnTrainBegin has reset it.
+func (c *checkpoint) restore(n *Network, opt *Optimizer, opts *TrainOptions, p *Progress, history *History) error {
+	weights := n.weights()
+	if len(weights) != len(c.Weights) {
+		return fmt.Errorf("nn: checkpoint has %d layers, but the network has %d", len(c.Weights), len(weights))
+	}
+	for l := range weights {
+		if len(weights[l]) != len(c.Weights[l]) {
+			return fmt.Errorf("nn: checkpoint has %d parameters for layer %d, but the network has %d", len(c.Weights[l]), l, len(weights[l]))
+		}
+	}
+	restored, err := decodeState(*opt, c.OptimizerType, c.Optimizer)
+	if err != nil {
+		return err
+	}
+	*opt = restored.(Optimizer)
+	if opts.Schedule != nil || c.ScheduleType != "" {
+		restored, err := decodeState(opts.Schedule, c.ScheduleType, c.Schedule)
+		if err != nil {
+			return err
+		}
+		opts.Schedule = restored.(Schedule)
+	}
+
+	n.setWeights(c.Weights)
+	n.seed = c.Seed
+	n.source.restore(c.Seed, c.Draws)
+	p.Epoch, p.Step, p.LearningRate, p.stop = c.Epoch, c.Step, c.LearningRate, c.Stopped
+	history.Epochs = append(history.Epochs, c.History.Epochs...)
+	return nil
+}
+
+func (c *checkpoint) restoreEarlyStopping(e *EarlyStopping) {
+	if e == nil || c.EarlyStopping == nil {
+		return
+	}
+	s := c.EarlyStopping
+	e.BestEpoch, e.Best, e.StoppedEpoch, e.wait, e.bestWeights = s.BestEpoch, s.Best, s.StoppedEpoch, s.Wait, s.BestWeights
+}
+
+// encodeState returns the type of v, an optimizer or a schedule, and the gob encoding of its exported fields,
+// which hold its state. Types without exported fields, such as SGD, have no state and are not encoded.
+func encodeState(v any) (string, []byte, error) {
+	if v == nil {
+		return "", nil, nil
+	}
+	name := fmt.Sprintf("%T", v)
+	if !hasExportedFields(reflect.TypeOf(v)) {
+		return name, nil, nil
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return "", nil, fmt.Errorf("nn: checkpoint: %s: %w", name, err)
+	}
+	return name, buf.Bytes(), nil
+}
+
+// decodeState restores into v the state encoded by encodeState. v must have the type that was encoded.
+// A pointer is updated in place. Other values cannot be, so decodeState returns the restored value.
+func decodeState(v any, name string, data []byte) (any, error) {
+	if got := fmt.Sprintf("%T", v); got != name {
+		return nil, fmt.Errorf("nn: checkpoint was saved with %s, not %s", name, got)
+	}
+	if data == nil {
+		return v, nil
+	}
+	t := reflect.TypeOf(v)
+	isPointer := t.Kind() == reflect.Pointer
+	if isPointer {
+		t = t.Elem()
+	}
+	// Decode into a zero value: gob leaves alone the fields whose encoded value is zero.
+	decoded := reflect.New(t)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(decoded.Interface()); err != nil {
+		return nil, fmt.Errorf("nn: checkpoint: %s: %w", name, err)
+	}
+	if isPointer {
+		reflect.ValueOf(v).Elem().Set(decoded.Elem())
+		return v, nil
+	}
+	return decoded.Elem().Interface(), nil
+}
+
+// hasExportedFields reports whether t, or what it points to, is not a struct without exported fields.
+func hasExportedFields(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+// Resume continues the training run whose last checkpoint was written to path, as set in TrainOptions.CheckpointPath,
+// as if it had never been interrupted. The network must be built as it was for the interrupted run, and opts must be
+// the same, with a new optimizer and schedule of the same types: their state is restored from the checkpoint, as are
+// the weights, the epoch and step counters, the sources of randomness and the state of the EarlyStopping.
+// The history returned includes the epochs completed before the checkpoint. A run that a callback stopped
+// is not trained further: only OnTrainEnd is called, so that EarlyStopping can restore the best weights.
+// If there is no checkpoint at path, the error satisfies errors.Is(err, os.ErrNotExist).
+func (n *Network) Resume(ctx context.Context, path string, inputs, targets [][]float64, opts TrainOptions) (*History, error) {
+	c, err := loadCheckpoint(path)
+	if err != nil {
+		return nil, err
+	}
+	return n.fit(ctx, inputs, targets, opts, c)
+}
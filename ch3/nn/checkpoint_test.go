@@ -0,0 +1,125 @@
+package nn
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNetwork_Resume(t *testing.T) {
+	inputs := [][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}, {0.5, 0.5}, {0.2, 0.9}}
+	targets := [][]float64{{0}, {1}, {1}, {0}, {1}, {1}}
+	build := func() *Network {
+		net := NewNetwork()
+		net.SetSeed(7)
+		net.AddLayer(4, 1, ASigmoid, WithInitializer(GlorotUniform))
+		net.AddDropout(0.2)
+		net.AddLayer(2, 4, ATanh, WithInitializer(GlorotUniform))
+		return net
+	}
+	options := func(path string) TrainOptions {
+		return TrainOptions{
+			Epochs:         6,
+			BatchSize:      2,
+			Optimizer:      NewAdam(),
+			Schedule:       Warmup{Steps: 4, Schedule: NewReduceOnPlateau(0.1, 0.5, 1)},
+			Shuffle:        true,
+			Seed:           3,
+			ValInputs:      inputs,
+			ValTargets:     targets,
+			EarlyStopping:  &EarlyStopping{Patience: 100, RestoreBest: true},
+			CheckpointPath: path,
+		}
+	}
+
+	// An uninterrupted run.
+	full := build()
+	expected, err := full.FitContext(context.Background(), inputs, targets, options(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The same run interrupted after 3 epochs, then resumed from its checkpoint by a new process.
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := options(path)
+	opts.Callbacks = []Callback{CallbackFuncs{EpochEnd: func(p *Progress) {
+		if p.Epoch == 2 {
+			cancel()
+		}
+	}}}
+	if _, err := build().FitContext(ctx, inputs, targets, opts); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the first run to be canceled, but got %v", err)
+	}
+	resumed := build()
+	actual, err := resumed.Resume(context.Background(), path, inputs, targets, options(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(actual.Epochs) != len(expected.Epochs) {
+		t.Fatalf("Expected %d epochs in the history, but got %d", len(expected.Epochs), len(actual.Epochs))
+	}
+	for i := range expected.Epochs {
+		if a, e := actual.Epochs[i].Metrics["loss"], expected.Epochs[i].Metrics["loss"]; a != e {
+			t.Errorf("Epoch %d: expected loss %v, but got %v", i, e, a)
+		}
+	}
+	aw, ew := resumed.weights(), full.weights()
+	for l := range ew {
+		for i := range ew[l] {
+			if aw[l][i] != ew[l][i] {
+				t.Fatalf("Layer %d: expected weights %v, but got %v", l, ew[l], aw[l])
+			}
+		}
+	}
+
+	// A run stopped by a callback is over: resuming it trains no further epoch and keeps its final weights.
+	stoppedPath := filepath.Join(t.TempDir(), "stopped")
+	opts = options(stoppedPath)
+	opts.Callbacks = []Callback{CallbackFuncs{EpochEnd: func(p *Progress) {
+		if p.Epoch == 2 {
+			p.Stop()
+		}
+	}}}
+	stopped := build()
+	if _, err := stopped.FitContext(context.Background(), inputs, targets, opts); err != nil {
+		t.Fatal(err)
+	}
+	resumed = build()
+	actual, err = resumed.Resume(context.Background(), stoppedPath, inputs, targets, options(stoppedPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual.Epochs) != 3 {
+		t.Errorf("Expected 3 epochs in the history of a stopped run, but got %d", len(actual.Epochs))
+	}
+	aw, ew = resumed.weights(), stopped.weights()
+	for l := range ew {
+		for i := range ew[l] {
+			if aw[l][i] != ew[l][i] {
+				t.Fatalf("Layer %d: expected the weights of the stopped run %v, but got %v", l, ew[l], aw[l])
+			}
+		}
+	}
+
+	if _, err := build().Resume(context.Background(), filepath.Join(t.TempDir(), "missing"), inputs, targets, options("")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected os.ErrNotExist without a checkpoint, but got %v", err)
+	}
+	opts = options(path)
+	opts.Optimizer = NewRMSProp(0.9)
+	if _, err := build().Resume(context.Background(), path, inputs, targets, opts); err == nil {
+		t.Error("Expected an error when resuming with another optimizer")
+	}
+
+	badPath := filepath.Join(t.TempDir(), "future")
+	if err := (&checkpoint{Version: checkpointVersion + 1}).save(badPath); err != nil {
+		t.Fatal(err)
+	}
+	var versionErr *VersionError
+	if _, err := build().Resume(context.Background(), badPath, inputs, targets, options("")); !errors.As(err, &versionErr) || versionErr.What != "checkpoint" || versionErr.Max != checkpointVersion {
+		t.Errorf("Expected a checkpoint *VersionError, but got %v", err)
+	}
+}
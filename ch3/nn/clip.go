@@ -0,0 +1,50 @@
+package nn
+
+import "math"
+
+// clipGradients clips grads in place. Every element is first clipped to [-value, value], then all the
+// gradients together are scaled down so that their global L2 norm is at most maxNorm. A zero value or
+// maxNorm disables the matching clipping. It returns the global norm before clipping and whether any
+// gradient was changed.
+func clipGradients(grads [][]float64, value, maxNorm float64) (norm float64, clipped bool) {
+	for _, g := range grads {
+		for _, x := range g {
+			norm += x * x
+		}
+	}
+	norm = math.Sqrt(norm)
+
+	if value > 0 {
+		for _, g := range grads {
+			for i := range g {
+				if g[i] > value || g[i] < -value {
+					g[i] = math.Max(-value, math.Min(value, g[i]))
+					clipped = true
+				}
+			}
+		}
+	}
+
+	if maxNorm > 0 {
+		clippedNorm := norm
+		if clipped { // Clipping by value may have reduced the norm already.
+			clippedNorm = 0
+			for _, g := range grads {
+				for _, x := range g {
+					clippedNorm += x * x
+				}
+			}
+			clippedNorm = math.Sqrt(clippedNorm)
+		}
+		if clippedNorm > maxNorm {
+			scale := maxNorm / clippedNorm
+			for _, g := range grads {
+				for i := range g {
+					g[i] *= scale
+				}
+			}
+			clipped = true
+		}
+	}
+	return norm, clipped
+}
@@ -0,0 +1,35 @@
+package nn
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClipGradients(t *testing.T) {
+	// Test case 1: clipping by value only touches the large elements.
+	grads := [][]float64{{3, -0.5}, {-4}}
+	norm, clipped := clipGradients(grads, 1, 0)
+	if !approxEqual(norm, math.Sqrt(25.25)) || !clipped {
+		t.Errorf("Expected norm %f and clipping, but got %f and %v", math.Sqrt(25.25), norm, clipped)
+	}
+	if grads[0][0] != 1 || grads[0][1] != -0.5 || grads[1][0] != -1 {
+		t.Errorf("Expected [[1 -0.5] [-1]], but got %v", grads)
+	}
+
+	// Test case 2: clipping by norm keeps the direction.
+	grads = [][]float64{{3}, {-4}}
+	if _, clipped := clipGradients(grads, 0, 1); !clipped || !approxEqual(grads[0][0], 0.6) || !approxEqual(grads[1][0], -0.8) {
+		t.Errorf("Expected [[0.6] [-0.8]], but got %v", grads)
+	}
+
+	// Test case 3: small gradients are left alone.
+	grads = [][]float64{{0.1}, {0.2}}
+	if _, clipped := clipGradients(grads, 1, 1); clipped || grads[0][0] != 0.1 || grads[1][0] != 0.2 {
+		t.Errorf("Expected unchanged gradients, but got %v", grads)
+	}
+}
+
+// Function to approximate floating point comparison
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 0.000001
+}
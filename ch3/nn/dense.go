@@ -0,0 +1,211 @@
+package nn
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/mreza101/gonn/ch3/tensor"
+)
+
+// Dense is a fully-connected layer of perceptrons: every output unit applies the activation function
+// of the layer to a weighted sum of all the inputs.
+type Dense struct {
+	numInputs int // Number of inputs, kept apart from w so that it is known even without output units.
+
+	w     [][]float64    // Each row correspond to one output unit for this layer. Each row has one extra element (the first one) for bias.
+	g     ActivationType // Type of activation function for all the output units of this layer.
+	grads [][]float64    // Gradient of the error with respect to w, accumulated over a batch. Same shape as w.
+	reg   Regularizer    // Regularization of w.
+
+	params     []float64 // Storage of w, row after row. This is what optimizers update.
+	paramGrads []float64 // Storage of grads, row after row.
+
+	inputs, sums, outputs [][]float64 // Inputs, weighted sums and outputs of the last forward pass in training mode, kept for backward.
+}
+
+// NewDense creates a new layer of perceptrons with random weights and bias.
+// By default weights and biases are drawn uniformly from [0, 1). Use WithInitializer and
+// WithBiasInitializer to choose other initializers.
+func NewDense(numInputs, numOutputs int, act ActivationType, opts ...LayerOption) *Dense {
+	config := layerConfig{weightInit: RandomUniform(0, 1), biasInit: RandomUniform(0, 1)}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.rng == nil {
+		config.rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+
+	params := make([]float64, numOutputs*(numInputs+1))
+	for i := range params {
+		if i%(numInputs+1) == 0 { // First column of each row.
+			params[i] = config.biasInit(config.rng, numInputs, numOutputs)
+		} else {
+			params[i] = config.weightInit(config.rng, numInputs, numOutputs)
+		}
+	}
+	paramGrads := make([]float64, len(params))
+	return &Dense{
+		numInputs:  numInputs,
+		w:          rows(params, numInputs+1),
+		g:          act,
+		reg:        config.reg,
+		grads:      rows(paramGrads, numInputs+1),
+		params:     params,
+		paramGrads: paramGrads,
+	}
+}
+
+// rows cuts data into rows of n elements, which share their elements with data.
+func rows(data []float64, n int) [][]float64 {
+	m, err := tensor.Reshape(data, len(data)/n, n)
+	if err != nil {
+		panic(err) // Unreachable: len(data) is a multiple of n.
+	}
+	return m
+}
+
+// NumInputs returns the number of inputs of the layer.
+func (p *Dense) NumInputs() int {
+	return p.numInputs
+}
+
+// NumOutputs returns the number of output units of the layer.
+func (p *Dense) NumOutputs() int {
+	return len(p.w)
+}
+
+// NewLayer is NewDense, from the time Dense was the only kind of layer.
+//
+// Deprecated: use NewDense.
+func NewLayer(numInputs, numOutputs int, act ActivationType, opts ...LayerOption) *Dense {
+	return NewDense(numInputs, numOutputs, act, opts...)
+}
+
+func dot(a, b []float64) float64 {
+	result := 0.0
+	for i := range a {
+		result += a[i] * b[i]
+	}
+	return result
+}
+
+// FeedForward calculates the output of the perceptron for a given input.
+func (p *Dense) FeedForward(inputs []float64) []float64 {
+	return activate(p.g, p.weightedSums(inputs))
+}
+
+// FeedForwardChecked is FeedForward returning a *ShapeError if inputs does not have NumInputs elements.
+func (p *Dense) FeedForwardChecked(inputs []float64) ([]float64, error) {
+	if len(inputs) != p.NumInputs() {
+		return nil, &ShapeError{What: "inputs", Layer: -1, Sample: -1, Expected: p.NumInputs(), Actual: len(inputs)}
+	}
+	return p.FeedForward(inputs), nil
+}
+
+// Forward implements Layer.
+func (p *Dense) Forward(inputs [][]float64, training bool) [][]float64 {
+	sums := make([][]float64, len(inputs))
+	outputs := make([][]float64, len(inputs))
+	for t := range inputs {
+		sums[t] = p.weightedSums(inputs[t])
+		outputs[t] = activate(p.g, sums[t])
+	}
+	if training {
+		p.inputs, p.sums, p.outputs = inputs, sums, outputs
+	}
+	return outputs
+}
+
+// Backward implements Layer.
+func (p *Dense) Backward(outputGrads [][]float64) [][]float64 {
+	deltas := make([][]float64, len(outputGrads))
+	for t := range outputGrads {
+		deltas[t] = activationDeltas(p.g, p.sums[t], p.outputs[t], outputGrads[t])
+	}
+	return p.backwardDeltas(deltas)
+}
+
+// backwardDeltas is backward given the derivative of the loss with respect to the weighted sums of the layer
+// instead of its outputs.
+func (p *Dense) backwardDeltas(deltas [][]float64) [][]float64 {
+	inputGrads := make([][]float64, len(deltas))
+	for t := range deltas {
+		p.accumulate(deltas[t], p.inputs[t])
+		inputGrads[t] = make([]float64, len(p.inputs[t]))
+		for j := range inputGrads[t] {
+			for k := range p.w {
+				inputGrads[t][j] += deltas[t][k] * p.w[k][j+1] // Column 0 holds the bias, so input j is column j+1.
+			}
+		}
+	}
+	return inputGrads
+}
+
+// Params implements Layer.
+func (p *Dense) Params() []float64 {
+	return p.params
+}
+
+// Grads implements Layer.
+func (p *Dense) Grads() []float64 {
+	return p.paramGrads
+}
+
+// weightedSums calculates the input of the activation function of every output unit.
+func (p *Dense) weightedSums(inputs []float64) []float64 {
+	sums := make([]float64, len(p.w))
+	for i := range sums {
+		sums[i] = dot(inputs, p.w[i][1:]) + p.w[i][0]
+	}
+	return sums
+}
+
+// accumulate adds the gradient of one sample to the gradients of the layer.
+// deltas[i] is the derivative of the error with respect to the weighted sum of output unit i.
+func (p *Dense) accumulate(deltas, inputs []float64) {
+	for i := range p.grads {
+		p.grads[i][0] += deltas[i] // Gradient of the bias.
+		for j := range inputs {
+			p.grads[i][j+1] += deltas[i] * inputs[j]
+		}
+	}
+}
+
+// Train trains a layer of perceptrons on the given inputs and targets, minimizing half the squared error
+// plus the penalty of the regularizer of the layer, as Network.Train does.
+func (p *Dense) Train(inputs []float64, targets []float64, learningRate float64) {
+	p.train(inputs, targets, learningRate)
+}
+
+// train is Train returning the loss of the sample before the update.
+func (p *Dense) train(inputs []float64, targets []float64, learningRate float64) float64 {
+	sums := p.weightedSums(inputs)
+	outputs := activate(p.g, sums)
+	loss := squaredError{}.Value(outputs, targets) + p.penalty()
+	p.accumulate(activationDeltas(p.g, sums, outputs, squaredError{}.Gradient(outputs, targets)), inputs)
+	p.addPenaltyGradients()
+	SGD{}.Update([][]float64{p.params}, [][]float64{p.paramGrads}, learningRate)
+	p.decay(learningRate)
+	clear(p.paramGrads)
+	return loss
+}
+
+// TrainAll trains the perceptron on a given set of training data.
+// It returns the history of the mean training loss of every epoch.
+func (p *Dense) TrainAll(inputs, targets [][]float64, epochs int, learningRate float64) *History {
+	history := &History{}
+	for i := 0; i < epochs; i++ {
+		start := time.Now()
+		total := 0.0
+		for n := range inputs {
+			total += p.train(inputs[n], targets[n], learningRate)
+		}
+		history.Epochs = append(history.Epochs, EpochRecord{
+			Epoch:        i,
+			Metrics:      map[string]float64{"loss": total / float64(len(inputs))},
+			LearningRate: learningRate,
+			Seconds:      time.Since(start).Seconds(),
+		})
+	}
+	return history
+}
@@ -0,0 +1,81 @@
+package nn
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Dropout is a layer that, in training mode, sets each of its inputs to 0 with probability Rate and scales the others
+// by 1/(1-Rate), so that their expected value does not change. In inference mode it passes its inputs through,
+// which is why the network does not need to be rescaled after training.
+type Dropout struct {
+	Rate float64 // Probability of dropping an input, in [0, 1).
+
+	rng  *rand.Rand
+	mask [][]float64 // Factor applied to each input by the last forward pass in training mode: 0 or 1/(1-Rate).
+}
+
+// NewDropout creates a dropout layer that drops inputs with probability rate, drawing from rng.
+// If rng is nil, the layer draws from a source seeded by the global math/rand functions.
+// NewDropout panics if rate is not in [0, 1).
+func NewDropout(rate float64, rng *rand.Rand) *Dropout {
+	if !validRate(rate) {
+		panic(fmt.Sprintf("nn: dropout rate must be in [0, 1), got %g", rate))
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+	return &Dropout{Rate: rate, rng: rng}
+}
+
+// validRate reports whether rate is a valid dropout rate, in [0, 1).
+func validRate(rate float64) bool {
+	return rate >= 0 && rate < 1
+}
+
+// Forward implements Layer.
+func (d *Dropout) Forward(inputs [][]float64, training bool) [][]float64 {
+	if !training || d.Rate == 0 {
+		d.mask = nil
+		return inputs
+	}
+	d.mask = make([][]float64, len(inputs))
+	outputs := make([][]float64, len(inputs))
+	scale := 1 / (1 - d.Rate)
+	for t := range inputs {
+		d.mask[t] = make([]float64, len(inputs[t]))
+		outputs[t] = make([]float64, len(inputs[t]))
+		for i := range inputs[t] {
+			if d.rng.Float64() >= d.Rate {
+				d.mask[t][i] = scale
+				outputs[t][i] = inputs[t][i] * scale
+			}
+		}
+	}
+	return outputs
+}
+
+// Backward implements Layer.
+func (d *Dropout) Backward(outputGrads [][]float64) [][]float64 {
+	if d.mask == nil {
+		return outputGrads
+	}
+	inputGrads := make([][]float64, len(outputGrads))
+	for t := range outputGrads {
+		inputGrads[t] = make([]float64, len(outputGrads[t]))
+		for i := range outputGrads[t] {
+			inputGrads[t][i] = outputGrads[t][i] * d.mask[t][i]
+		}
+	}
+	return inputGrads
+}
+
+// Params implements Layer.
+func (d *Dropout) Params() []float64 {
+	return nil
+}
+
+// Grads implements Layer.
+func (d *Dropout) Grads() []float64 {
+	return nil
+}
@@ -0,0 +1,82 @@
+package nn
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDropout(t *testing.T) {
+	d := NewDropout(0.25, rand.New(rand.NewSource(1)))
+	inputs := make([]float64, 10000)
+	for i := range inputs {
+		inputs[i] = 1
+	}
+
+	outputs := d.Forward([][]float64{inputs}, false)[0]
+	for i := range outputs {
+		if outputs[i] != 1 {
+			t.Fatalf("Expected inference mode to pass inputs through, but got %f", outputs[i])
+		}
+	}
+
+	outputs = d.Forward([][]float64{inputs}, true)[0]
+	dropped, sum := 0, 0.0
+	for i := range outputs {
+		if outputs[i] == 0 {
+			dropped++
+		} else if !approxEqual(outputs[i], 1/0.75) {
+			t.Fatalf("Expected kept inputs to be scaled to %f, but got %f", 1/0.75, outputs[i])
+		}
+		sum += outputs[i]
+	}
+	if rate := float64(dropped) / float64(len(inputs)); math.Abs(rate-0.25) > 0.02 {
+		t.Errorf("Expected about 25%% of the inputs dropped, but got %.1f%%", 100*rate)
+	}
+	if mean := sum / float64(len(inputs)); math.Abs(mean-1) > 0.05 {
+		t.Errorf("Expected the mean to stay about 1, but got %f", mean)
+	}
+
+	grads := d.Backward([][]float64{inputs})[0]
+	for i := range grads {
+		if grads[i] != outputs[i] {
+			t.Fatalf("Expected the gradient to go through the same mask as the inputs, but got %f for output %f", grads[i], outputs[i])
+		}
+	}
+}
+
+func TestNewDropout_InvalidRate(t *testing.T) {
+	for _, rate := range []float64{-1, 1, math.NaN()} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for rate %g", rate)
+				}
+			}()
+			NewDropout(rate, nil)
+		}()
+	}
+}
+
+func TestNetwork_SetTraining(t *testing.T) {
+	net := NewNetwork()
+	net.SetSeed(1)
+	net.AddLayer(20, 1, ASigmoid, WithInitializer(Constant(0.01)))
+	net.AddDropout(0.5)
+	inputs := make([]float64, 20)
+	for i := range inputs {
+		inputs[i] = 1
+	}
+
+	if net.Training() {
+		t.Fatal("Expected a new network to be in inference mode")
+	}
+	if a, b := net.FeedForward(inputs), net.FeedForward(inputs); a[0] != b[0] {
+		t.Errorf("Expected identical outputs in inference mode, but got %f and %f", a[0], b[0])
+	}
+
+	net.SetTraining(true)
+	if a, b := net.FeedForward(inputs), net.FeedForward(inputs); a[0] == b[0] {
+		t.Errorf("Expected dropout to change the outputs in training mode, but got %f twice", a[0])
+	}
+}
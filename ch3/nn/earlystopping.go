@@ -0,0 +1,97 @@
+package nn
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// EarlyStopping is a Callback that stops Network.Fit once a monitored quantity has stopped improving.
+type EarlyStopping struct {
+	nopCallback
+
+	Monitor     string  // Quantity to watch: "val_loss" (the default), "val_accuracy" or "loss". Accuracies are maximized, losses minimized.
+	Patience    int     // Number of epochs without improvement after which training stops.
+	MinDelta    float64 // Smallest change of the monitored quantity that counts as an improvement.
+	RestoreBest bool    // Restore the weights of the best epoch when training stops.
+
+	BestEpoch    int     // Set by Fit: epoch, counted from 0, with the best value of the monitored quantity.
+	Best         float64 // Set by Fit: best value of the monitored quantity.
+	StoppedEpoch int     // Set by Fit: epoch at which training stopped early, or -1 if it ran to the end.
+
+	wait        int         // Number of epochs since the last improvement.
+	bestWeights [][]float64 // Weights of the best epoch, if RestoreBest is set.
+}
+
+func (e *EarlyStopping) monitor() string {
+	if e.Monitor == "" {
+		return "val_loss"
+	}
+	return e.Monitor
+}
+
+func (e *EarlyStopping) maximize() bool {
+	return strings.HasSuffix(e.monitor(), "accuracy")
+}
+
+// check reports a monitored metric that Fit will not compute with opts.
+func (e *EarlyStopping) check(opts *TrainOptions) error {
+	switch e.monitor() {
+	case "loss":
+		return nil
+	case "val_loss", "val_accuracy":
+		if opts.ValInputs == nil {
+			return fmt.Errorf("nn: early stopping monitors %q, which needs validation data", e.monitor())
+		}
+		return nil
+	}
+	return fmt.Errorf("nn: early stopping monitors unknown metric %q", e.monitor())
+}
+
+// OnTrainBegin implements Callback. It resets e for a new training run.
+func (e *EarlyStopping) OnTrainBegin(p *Progress) {
+	e.BestEpoch = -1
+	e.Best = math.Inf(1)
+	if e.maximize() {
+		e.Best = math.Inf(-1)
+	}
+	e.StoppedEpoch = -1
+	e.wait = 0
+	e.bestWeights = nil
+}
+
+// OnEpochEnd implements Callback. It records the monitored metric of the epoch and stops training
+// when it has not improved for Patience epochs. Epochs without the metric are ignored.
+func (e *EarlyStopping) OnEpochEnd(p *Progress) {
+	value, ok := p.Metrics[e.monitor()]
+	if !ok {
+		return
+	}
+
+	improved := value < e.Best-e.MinDelta
+	if e.maximize() {
+		improved = value > e.Best+e.MinDelta
+	}
+	if improved {
+		e.Best = value
+		e.BestEpoch = p.Epoch
+		e.wait = 0
+		if e.RestoreBest {
+			e.bestWeights = p.Network.weights()
+		}
+		return
+	}
+
+	e.wait++
+	if e.wait >= e.Patience {
+		e.StoppedEpoch = p.Epoch
+		p.Stop()
+	}
+}
+
+// OnTrainEnd implements Callback. It puts back the weights of the best epoch, if they were kept.
+func (e *EarlyStopping) OnTrainEnd(p *Progress) {
+	if e.RestoreBest && e.bestWeights != nil {
+		p.Network.setWeights(e.bestWeights)
+	}
+}
@@ -0,0 +1,55 @@
+package nn
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEarlyStopping(t *testing.T) {
+	inputs := [][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}}
+	targets := [][]float64{{0}, {1}, {1}, {1}}
+	net := NewNetwork()
+	net.SetSeed(1)
+	net.AddLayer(2, 1, ASigmoid)
+
+	// A negative learning rate climbs the loss, so the first epoch is the best one.
+	es := &EarlyStopping{Monitor: "val_loss", Patience: 3, RestoreBest: true}
+	net.Fit(inputs, targets, TrainOptions{
+		Epochs:        100,
+		LearningRate:  -1,
+		ValInputs:     inputs,
+		ValTargets:    targets,
+		EarlyStopping: es,
+	})
+
+	if es.BestEpoch != 0 || es.StoppedEpoch != 3 {
+		t.Errorf("Expected best epoch 0 and stop at epoch 3, but got %d and %d", es.BestEpoch, es.StoppedEpoch)
+	}
+	if loss := net.Evaluate(inputs, targets, MSE{}); loss != es.Best {
+		t.Errorf("Expected the weights of the best epoch with loss %f, but got loss %f", es.Best, loss)
+	}
+}
+
+func TestFitContext_EarlyStoppingWithoutMetric(t *testing.T) {
+	inputs := [][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}}
+	targets := [][]float64{{0}, {1}, {1}, {1}}
+	for _, monitor := range []string{"", "val_accuracy", "accuracy"} {
+		net := NewNetwork()
+		net.AddLayer(2, 1, ASigmoid)
+		opts := TrainOptions{Epochs: 3, LearningRate: 0.5, EarlyStopping: &EarlyStopping{Monitor: monitor, Patience: 1}}
+		history, err := net.FitContext(context.Background(), inputs, targets, opts)
+		if err == nil {
+			t.Errorf("%q: expected an error without validation data", monitor)
+		}
+		if len(history.Epochs) != 0 {
+			t.Errorf("%q: expected no training, but got %d epochs", monitor, len(history.Epochs))
+		}
+	}
+
+	net := NewNetwork()
+	net.AddLayer(2, 1, ASigmoid)
+	opts := TrainOptions{Epochs: 3, LearningRate: 0.5, EarlyStopping: &EarlyStopping{Monitor: "loss", Patience: 1}}
+	if _, err := net.FitContext(context.Background(), inputs, targets, opts); err != nil {
+		t.Errorf("Expected no error when monitoring the training loss, but got %v", err)
+	}
+}
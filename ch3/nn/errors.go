@@ -0,0 +1,74 @@
+package nn
+
+import (
+	"fmt"
+
+	"github.com/mreza101/gonn/ch3/tensor"
+)
+
+// ErrShapeMismatch is matched, with errors.Is, by the errors returned for inputs or targets of the wrong size.
+// It is the same error as tensor.ErrShapeMismatch.
+var ErrShapeMismatch = tensor.ErrShapeMismatch
+
+// ShapeError reports inputs or targets whose size does not match what a layer or the network expects.
+type ShapeError struct {
+	What     string // What has the wrong size: "inputs", "targets" or "target rows", the number of targets given for a data set.
+	Layer    int    // Index of the layer expecting the size, 0 being the output layer, or -1 if the network as a whole expects it.
+	Sample   int    // Index of the sample in its data set, or -1 for a single sample.
+	Expected int
+	Actual   int
+}
+
+func (e *ShapeError) Error() string {
+	msg := "nn: "
+	if e.Sample >= 0 {
+		msg += fmt.Sprintf("sample %d: ", e.Sample)
+	}
+	if e.Layer >= 0 {
+		return msg + fmt.Sprintf("layer %d expects %d %s, got %d", e.Layer, e.Expected, e.What, e.Actual)
+	}
+	return msg + fmt.Sprintf("expected %d %s, got %d", e.Expected, e.What, e.Actual)
+}
+
+// Is makes errors.Is(err, ErrShapeMismatch) report true for a *ShapeError.
+func (e *ShapeError) Is(target error) bool {
+	return target == ErrShapeMismatch
+}
+
+// outputSize returns the number of outputs of the network for inputs of numInputs elements,
+// or a *ShapeError if a layer does not take the number of outputs of the layer before it.
+func (n *Network) outputSize(numInputs int) (int, error) {
+	size := numInputs
+	for i := len(n.layers) - 1; i >= 0; i-- {
+		if s, ok := n.layers[i].(Sized); ok {
+			if s.NumInputs() != size {
+				return 0, &ShapeError{What: "inputs", Layer: i, Sample: -1, Expected: s.NumInputs(), Actual: size}
+			}
+			size = s.NumOutputs()
+		}
+	}
+	return size, nil
+}
+
+// checkSample returns a *ShapeError if inputs or targets do not fit the network. targets may be nil.
+func (n *Network) checkSample(inputs, targets []float64) error {
+	size, err := n.outputSize(len(inputs))
+	if err == nil && targets != nil && len(targets) != size {
+		err = &ShapeError{What: "targets", Layer: -1, Sample: -1, Expected: size, Actual: len(targets)}
+	}
+	return err
+}
+
+// checkData returns a *ShapeError, with the index of the sample, if a sample of a data set does not fit the network.
+func (n *Network) checkData(inputs, targets [][]float64) error {
+	if len(targets) != len(inputs) {
+		return &ShapeError{What: "target rows", Layer: -1, Sample: -1, Expected: len(inputs), Actual: len(targets)}
+	}
+	for t := range inputs {
+		if err := n.checkSample(inputs[t], targets[t]); err != nil {
+			err.(*ShapeError).Sample = t
+			return err
+		}
+	}
+	return nil
+}
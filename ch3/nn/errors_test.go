@@ -0,0 +1,39 @@
+package nn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNetwork_Checked(t *testing.T) {
+	net := NewNetwork()
+	net.AddLayer(3, 2, ASigmoid)
+	net.AddDropout(0.5)
+	net.AddLayer(2, 3, ATanh)
+
+	if _, err := net.FeedForwardChecked([]float64{1, 2}); err != nil {
+		t.Errorf("Expected no error, but got %v", err)
+	}
+
+	_, err := net.FeedForwardChecked([]float64{1, 2, 3})
+	var shapeErr *ShapeError
+	if !errors.As(err, &shapeErr) || !errors.Is(err, ErrShapeMismatch) {
+		t.Fatalf("Expected a *ShapeError, but got %v", err)
+	}
+	if shapeErr.Layer != 2 || shapeErr.Expected != 2 || shapeErr.Actual != 3 {
+		t.Errorf("Expected layer 2 to expect 2 inputs and get 3, but got %+v", shapeErr)
+	}
+
+	if err := net.TrainChecked([]float64{1, 2}, []float64{1}, 0.1); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("Expected a shape mismatch for the targets, but got %v", err)
+	}
+
+	inputs := [][]float64{{1, 2}, {1, 2}, {1}}
+	targets := [][]float64{{1, 0}, {0, 1}, {1, 0}}
+	if _, err := net.TestChecked(inputs, targets); err == nil || err.Error() != "nn: sample 2: layer 2 expects 2 inputs, got 1" {
+		t.Errorf("Expected an error about sample 2, but got %v", err)
+	}
+	if _, err := net.TrainAllChecked(inputs[:2], targets, 1, 0.1); err == nil || err.Error() != "nn: expected 2 target rows, got 3" {
+		t.Errorf("Expected an error about the number of targets, but got %v", err)
+	}
+}
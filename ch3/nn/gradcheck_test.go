@@ -0,0 +1,32 @@
+package nn
+
+import (
+	"math"
+	"testing"
+)
+
+// finiteDifference returns the central finite difference of f with respect to *x, leaving *x unchanged.
+func finiteDifference(x *float64, f func() float64) float64 {
+	const h = 1e-6
+	v := *x
+	*x = v + h
+	plus := f()
+	*x = v - h
+	minus := f()
+	*x = v
+	return (plus - minus) / (2 * h)
+}
+
+// checkGradients compares the gradients accumulated by backprop in the layers of net against central
+// finite differences of lossOf, which computes the loss of net on the same samples.
+func checkGradients(t *testing.T, net *Network, lossOf func() float64) {
+	t.Helper()
+	for l, layer := range net.layers {
+		params, grads := layer.Params(), layer.Grads()
+		for i := range params {
+			if expected := finiteDifference(&params[i], lossOf); math.Abs(grads[i]-expected) > 1e-6 {
+				t.Errorf("Layer %d parameter %d: expected gradient %f, but got %f", l, i, expected, grads[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package nn
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// History records how a training run went, epoch by epoch.
+type History struct {
+	Epochs []EpochRecord `json:"epochs"`
+}
+
+// EpochRecord holds what was measured during one epoch.
+type EpochRecord struct {
+	Epoch        int                `json:"epoch"`         // Counted from 0.
+	Metrics      map[string]float64 `json:"metrics"`       // Training "loss" and, with validation data, "val_loss" and "val_accuracy".
+	LearningRate float64            `json:"learning_rate"` // Learning rate of the last update of the epoch.
+	Seconds      float64            `json:"seconds"`       // Wall-clock duration of the epoch, including validation.
+}
+
+// epochRecordJSON is the JSON encoding of an EpochRecord. JSON has no NaN or infinities,
+// so non-finite values, such as the loss of a diverging run, are encoded as null.
+type epochRecordJSON struct {
+	Epoch        int                 `json:"epoch"`
+	Metrics      map[string]*float64 `json:"metrics"`
+	LearningRate *float64            `json:"learning_rate"`
+	Seconds      float64             `json:"seconds"`
+}
+
+// MarshalJSON implements json.Marshaler, encoding NaN and infinities as null.
+func (e EpochRecord) MarshalJSON() ([]byte, error) {
+	finite := func(v float64) *float64 {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil
+		}
+		return &v
+	}
+	r := epochRecordJSON{Epoch: e.Epoch, LearningRate: finite(e.LearningRate), Seconds: e.Seconds}
+	if e.Metrics != nil {
+		r.Metrics = make(map[string]*float64, len(e.Metrics))
+		for name, v := range e.Metrics {
+			r.Metrics[name] = finite(v)
+		}
+	}
+	return json.Marshal(r)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoding null as NaN.
+func (e *EpochRecord) UnmarshalJSON(data []byte) error {
+	var r epochRecordJSON
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+	value := func(v *float64) float64 {
+		if v == nil {
+			return math.NaN()
+		}
+		return *v
+	}
+	*e = EpochRecord{Epoch: r.Epoch, LearningRate: value(r.LearningRate), Seconds: r.Seconds}
+	if r.Metrics != nil {
+		e.Metrics = make(map[string]float64, len(r.Metrics))
+		for name, v := range r.Metrics {
+			e.Metrics[name] = value(v)
+		}
+	}
+	return nil
+}
+
+// Metric returns the values of the named metric, one per epoch. Epochs that lack it yield NaN.
+func (h *History) Metric(name string) []float64 {
+	result := make([]float64, len(h.Epochs))
+	for i, e := range h.Epochs {
+		v, ok := e.Metrics[name]
+		if !ok {
+			v = math.NaN()
+		}
+		result[i] = v
+	}
+	return result
+}
+
+// Loss returns the training loss of every epoch.
+func (h *History) Loss() []float64 {
+	return h.Metric("loss")
+}
+
+// ValLoss returns the validation loss of every epoch, or NaNs if there was no validation data.
+func (h *History) ValLoss() []float64 {
+	return h.Metric("val_loss")
+}
+
+// metricNames returns the names of all the metrics recorded, sorted.
+func (h *History) metricNames() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, e := range h.Epochs {
+		for name := range e.Metrics {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteCSV writes the history as CSV with a header row and one row per epoch.
+// The columns are epoch, learning_rate, seconds and then every metric, sorted by name.
+func (h *History) WriteCSV(w io.Writer) error {
+	names := h.metricNames()
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"epoch", "learning_rate", "seconds"}, names...)); err != nil {
+		return err
+	}
+	format := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	for _, e := range h.Epochs {
+		row := []string{strconv.Itoa(e.Epoch), format(e.LearningRate), format(e.Seconds)}
+		for _, name := range names {
+			if v, ok := e.Metrics[name]; ok {
+				row = append(row, format(v))
+			} else {
+				row = append(row, "")
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the history as a JSON document. Non-finite values are written as null.
+func (h *History) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(h)
+}
+
+// historyRecorder is the Callback with which Network.Fit fills a History.
+type historyRecorder struct {
+	nopCallback
+	history *History
+	start   time.Time
+}
+
+func (r *historyRecorder) OnEpochBegin(p *Progress) {
+	r.start = time.Now()
+}
+
+func (r *historyRecorder) OnEpochEnd(p *Progress) {
+	metrics := make(map[string]float64, len(p.Metrics))
+	for name, v := range p.Metrics {
+		metrics[name] = v
+	}
+	r.history.Epochs = append(r.history.Epochs, EpochRecord{
+		Epoch:        p.Epoch,
+		Metrics:      metrics,
+		LearningRate: p.LearningRate,
+		Seconds:      time.Since(r.start).Seconds(),
+	})
+}
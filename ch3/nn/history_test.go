@@ -0,0 +1,65 @@
+package nn
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestFit_History(t *testing.T) {
+	inputs := [][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}}
+	targets := [][]float64{{0}, {1}, {1}, {1}}
+	net := NewNetwork()
+	net.AddLayer(2, 1, ASigmoid)
+
+	history := net.Fit(inputs, targets, TrainOptions{Epochs: 3, LearningRate: 0.5, ValInputs: inputs, ValTargets: targets})
+	if len(history.Epochs) != 3 || len(history.Loss()) != 3 || len(history.ValLoss()) != 3 {
+		t.Fatalf("Expected 3 epochs, but got %d", len(history.Epochs))
+	}
+	if history.Epochs[2].Epoch != 2 || history.Epochs[2].LearningRate != 0.5 {
+		t.Errorf("Expected epoch 2 with learning rate 0.5, but got %+v", history.Epochs[2])
+	}
+
+	var buf bytes.Buffer
+	if err := history.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || lines[0] != "epoch,learning_rate,seconds,loss,val_accuracy,val_loss" {
+		t.Errorf("Expected a header and 3 rows, but got %q", lines)
+	}
+
+	buf.Reset()
+	if err := history.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded History
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Epochs[1].Metrics["val_loss"] != history.Epochs[1].Metrics["val_loss"] {
+		t.Errorf("Expected the JSON to round trip, but got %+v", decoded.Epochs[1])
+	}
+}
+
+func TestHistory_WriteJSONNonFinite(t *testing.T) {
+	history := &History{Epochs: []EpochRecord{
+		{Epoch: 0, Metrics: map[string]float64{"loss": math.Inf(1), "val_loss": math.NaN(), "val_accuracy": 0.5}, LearningRate: 0.1},
+	}}
+	var buf bytes.Buffer
+	if err := history.WriteJSON(&buf); err != nil {
+		t.Fatalf("Expected no error for non-finite values, but got %v", err)
+	}
+	if !strings.Contains(buf.String(), `"loss": null`) {
+		t.Errorf("Expected an infinite loss to be written as null, but got %s", buf.String())
+	}
+	var decoded History
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if m := decoded.Epochs[0].Metrics; !math.IsNaN(m["loss"]) || !math.IsNaN(m["val_loss"]) || m["val_accuracy"] != 0.5 {
+		t.Errorf("Expected null to decode as NaN, but got %v", m)
+	}
+}
@@ -0,0 +1,93 @@
+package nn
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Initializer returns the initial value of one weight of a layer with fanIn inputs and fanOut output units.
+// Random initializers must draw from rng so that the initialization can be reproduced.
+// Any function with this signature can be used to initialize a layer.
+type Initializer func(rng *rand.Rand, fanIn, fanOut int) float64
+
+// LayerOption configures a dense layer created with NewDense or Network.AddLayer.
+type LayerOption func(*layerConfig)
+
+type layerConfig struct {
+	weightInit Initializer // Initializer of the weights of the inputs.
+	biasInit   Initializer // Initializer of the biases, the first column of the weights.
+	rng        *rand.Rand  // Source of randomness of the initializers.
+	reg        Regularizer // Regularization of the weights.
+}
+
+// WithInitializer sets the initializer of the weights of a layer, not including its biases.
+// The default is RandomUniform(0, 1).
+func WithInitializer(init Initializer) LayerOption {
+	return func(c *layerConfig) { c.weightInit = init }
+}
+
+// WithBiasInitializer sets the initializer of the biases of a layer. The default is RandomUniform(0, 1).
+func WithBiasInitializer(init Initializer) LayerOption {
+	return func(c *layerConfig) { c.biasInit = init }
+}
+
+// WithRand sets the source of randomness used to initialize a layer. By default a layer
+// draws from a source seeded by the global math/rand functions, so it cannot be reproduced.
+func WithRand(rng *rand.Rand) LayerOption {
+	return func(c *layerConfig) { c.rng = rng }
+}
+
+// RandomUniform draws weights uniformly from [lo, hi).
+func RandomUniform(lo, hi float64) Initializer {
+	return func(rng *rand.Rand, fanIn, fanOut int) float64 {
+		return lo + (hi-lo)*rng.Float64()
+	}
+}
+
+// Constant initializes every weight to v.
+func Constant(v float64) Initializer {
+	return func(rng *rand.Rand, fanIn, fanOut int) float64 {
+		return v
+	}
+}
+
+// Zeros initializes every weight to 0. It is the usual choice for biases.
+func Zeros(rng *rand.Rand, fanIn, fanOut int) float64 {
+	return 0
+}
+
+// uniform draws from [-limit, limit).
+func uniform(rng *rand.Rand, limit float64) float64 {
+	return limit * (2*rng.Float64() - 1)
+}
+
+// GlorotUniform, also known as Xavier uniform, keeps the variance of activations and gradients
+// balanced across layers. It suits sigmoid, tanh and softmax units.
+func GlorotUniform(rng *rand.Rand, fanIn, fanOut int) float64 {
+	return uniform(rng, math.Sqrt(6/float64(fanIn+fanOut)))
+}
+
+// GlorotNormal is the normal variant of GlorotUniform.
+func GlorotNormal(rng *rand.Rand, fanIn, fanOut int) float64 {
+	return rng.NormFloat64() * math.Sqrt(2/float64(fanIn+fanOut))
+}
+
+// HeUniform compensates for ReLU units zeroing half of their inputs.
+func HeUniform(rng *rand.Rand, fanIn, fanOut int) float64 {
+	return uniform(rng, math.Sqrt(6/float64(fanIn)))
+}
+
+// HeNormal is the normal variant of HeUniform.
+func HeNormal(rng *rand.Rand, fanIn, fanOut int) float64 {
+	return rng.NormFloat64() * math.Sqrt(2/float64(fanIn))
+}
+
+// LeCunUniform keeps the variance of the weighted sums at 1 for unit-variance inputs.
+func LeCunUniform(rng *rand.Rand, fanIn, fanOut int) float64 {
+	return uniform(rng, math.Sqrt(3/float64(fanIn)))
+}
+
+// LeCunNormal is the normal variant of LeCunUniform.
+func LeCunNormal(rng *rand.Rand, fanIn, fanOut int) float64 {
+	return rng.NormFloat64() * math.Sqrt(1/float64(fanIn))
+}
@@ -0,0 +1,28 @@
+package nn
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mreza101/gonn/ch3/tensor"
+)
+
+func TestNewDense_Initializers(t *testing.T) {
+	layer := NewDense(200, 100, AReLU, WithInitializer(HeNormal), WithBiasInitializer(Constant(0.1)))
+
+	var weights []float64
+	for i := range layer.w {
+		if layer.w[i][0] != 0.1 {
+			t.Errorf("Expected bias 0.1, but got %f", layer.w[i][0])
+		}
+		weights = append(weights, layer.w[i][1:]...)
+	}
+
+	// He normal weights have zero mean and a variance of 2 / fanIn.
+	if mean := tensor.Mean(weights); math.Abs(mean) > 0.01 {
+		t.Errorf("Expected mean close to 0, but got %f", mean)
+	}
+	if variance := tensor.Variance(weights); math.Abs(variance-2.0/200) > 0.001 {
+		t.Errorf("Expected variance close to %f, but got %f", 2.0/200, variance)
+	}
+}
@@ -1,66 +1,35 @@
 package nn
 
-import (
-	"math/rand"
-)
-
-// Neuron holds the weights and activation function for a single neuron.
-type Layer struct {
-	w [][]float64    // Each row correspond to one output unit for this layer. Each row has one extra element (the first one) for bias.
-	g ActivationType // Type of activation function for all the output units of this layer.
-}
-
-// NewLayer creates a new perceptron with random weights and bias.
-func NewLayer(numInputs, numOutputs int, act ActivationType) *Layer {
-	weights := make([][]float64, numOutputs)
-	for i := range weights {
-		weights[i] = make([]float64, numInputs+1)
-		for j := range weights[i] {
-			weights[i][j] = rand.Float64()
-		}
-
-	}
-	return &Layer{w: weights, g: act}
-}
-
-func dot(a, b []float64) float64 {
-	result := 0.0
-	for i := range a {
-		result += a[i] * b[i]
-	}
-	return result
-}
-
-// FeedForward calculates the output of the perceptron for a given input.
-func (p *Layer) FeedForward(inputs []float64) []float64 {
-	acFunc := ActivationFuncs[p.g]
-
-	outputs := make([]float64, len(p.w))
-	for i := range outputs {
-		outputs[i] = acFunc(dot(inputs, p.w[i][1:]) + p.w[i][0])
-	}
-	return outputs
+// Layer is one stage of a Network, such as a Dense layer, an Activation, a Dropout or a normalization layer.
+// Layers process a batch of samples at once, one row per sample, so that they can normalize across the batch.
+// Any type implementing Layer can be added to a network with Network.Add.
+type Layer interface {
+	// Forward calculates the outputs of the layer for a batch of samples. In training mode the layer
+	// remembers what Backward needs, and may behave differently, as Dropout does.
+	Forward(inputs [][]float64, training bool) [][]float64
+	// Backward takes the derivative of the loss with respect to the outputs of the last Forward call in training mode,
+	// adds the gradients of the parameters of the layer to Grads and returns the derivative of the loss
+	// with respect to the inputs of the layer.
+	Backward(outputGrads [][]float64) [][]float64
+	// Params returns the storage of the trainable parameters of the layer, or nil if it has none.
+	// Optimizers update it in place, so it must stay the same slice for the life of the layer.
+	Params() []float64
+	// Grads returns the storage of the gradients accumulated by Backward, of the same length as Params.
+	// The network averages them over a batch, passes them to the optimizer and clears them after every update.
+	Grads() []float64
 }
 
-// Train trains a layer of perceptrons on the given inputs and targets.
-func (p *Layer) Train(inputs []float64, targets []float64, learningRate float64) {
-	acPrime := ActivationPrimes[p.g]
-	output := p.FeedForward(inputs)
-	for i := range p.w { // Iterate over all output units.
-		delta := (targets[i] - output[i]) * acPrime(output[i])
-		factor := learningRate * delta
-		p.w[i][0] += factor // Adjust bias.
-		for j := range inputs {
-			p.w[i][j+1] += factor * inputs[j]
-		}
-	}
+// Stateful is implemented by layers that keep state besides their parameters, such as the running statistics
+// of BatchNorm. The state is saved and restored along with the parameters.
+type Stateful interface {
+	// State returns the storage of the state of the layer.
+	State() []float64
 }
 
-// TrainAll trains the perceptron on a given set of training data.
-func (p *Layer) TrainAll(inputs, targets [][]float64, epochs int, learningRate float64) {
-	for i := 0; i < epochs; i++ {
-		for n := range inputs {
-			p.Train(inputs[n], targets[n], learningRate)
-		}
-	}
+// Sized is implemented by layers that take a fixed number of inputs, such as Dense.
+// The checked methods of Network, such as FeedForwardChecked, use it to validate inputs before feeding them.
+// Layers that do not implement it are assumed to have as many outputs as inputs.
+type Sized interface {
+	NumInputs() int
+	NumOutputs() int
 }
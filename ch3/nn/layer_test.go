@@ -0,0 +1,59 @@
+package nn
+
+import (
+	"fmt"
+	"testing"
+)
+
+// scale is a user-defined layer that multiplies all its inputs by a single parameter.
+type scale struct {
+	w, g   []float64
+	inputs [][]float64
+}
+
+func (s *scale) Forward(inputs [][]float64, training bool) [][]float64 {
+	if training {
+		s.inputs = inputs
+	}
+	outputs := make([][]float64, len(inputs))
+	for t := range inputs {
+		outputs[t] = make([]float64, len(inputs[t]))
+		for i := range inputs[t] {
+			outputs[t][i] = s.w[0] * inputs[t][i]
+		}
+	}
+	return outputs
+}
+
+func (s *scale) Backward(outputGrads [][]float64) [][]float64 {
+	inputGrads := make([][]float64, len(outputGrads))
+	for t := range outputGrads {
+		inputGrads[t] = make([]float64, len(outputGrads[t]))
+		for i := range outputGrads[t] {
+			s.g[0] += outputGrads[t][i] * s.inputs[t][i]
+			inputGrads[t][i] = outputGrads[t][i] * s.w[0]
+		}
+	}
+	return inputGrads
+}
+
+func (s *scale) Params() []float64 { return s.w }
+func (s *scale) Grads() []float64  { return s.g }
+
+func TestNetwork_Add(t *testing.T) {
+	for _, loss := range []Loss{MSE{}, CategoricalCrossEntropy{}} {
+		t.Run(fmt.Sprintf("%T", loss), func(t *testing.T) {
+			net := NewNetwork()
+			net.SetSeed(1)
+			net.AddActivation(ASoftmax)
+			net.AddLayer(3, 2, ASigmoid, WithInitializer(GlorotUniform))
+			net.Add(&scale{w: []float64{1.5}, g: []float64{0}})
+			net.AddLayer(2, 3, ATanh, WithInitializer(GlorotUniform))
+			inputs := []float64{0.5, -1}
+			targets := []float64{1, 0}
+
+			net.backprop([][]float64{inputs}, [][]float64{targets}, loss)
+			checkGradients(t, net, func() float64 { return loss.Value(net.FeedForward(inputs), targets) })
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package nn
+
+import "math"
+
+// LayerNorm is a layer that normalizes the inputs of every sample to zero mean and unit variance across
+// its features, then scales and shifts each feature by a learned gain and bias. Unlike BatchNorm, it does not
+// depend on the other samples of the batch, so it works the same in training and inference mode and with Train.
+type LayerNorm struct {
+	Epsilon float64 // Added to the variance to avoid dividing by 0.
+
+	gain, bias []float64 // Views of params.
+
+	params     []float64 // Storage of gain followed by bias.
+	paramGrads []float64 // Storage of the gradients of gain and bias.
+
+	normalized [][]float64 // Normalized inputs of the last forward pass in training mode, kept for backward.
+	invStd     []float64   // 1/sqrt(variance + Epsilon) of every sample of the last forward pass in training mode.
+}
+
+// NewLayerNorm creates a layer normalization layer for size inputs. The gains start at 1 and the biases at 0.
+func NewLayerNorm(size int) *LayerNorm {
+	params := make([]float64, 2*size)
+	for i := 0; i < size; i++ {
+		params[i] = 1
+	}
+	return &LayerNorm{
+		Epsilon:    1e-5,
+		gain:       params[:size],
+		bias:       params[size:],
+		params:     params,
+		paramGrads: make([]float64, 2*size),
+	}
+}
+
+// NumInputs implements Sized.
+func (l *LayerNorm) NumInputs() int {
+	return len(l.gain)
+}
+
+// NumOutputs implements Sized.
+func (l *LayerNorm) NumOutputs() int {
+	return len(l.gain)
+}
+
+// Forward implements Layer.
+func (l *LayerNorm) Forward(inputs [][]float64, training bool) [][]float64 {
+	normalized := make([][]float64, len(inputs))
+	invStd := make([]float64, len(inputs))
+	outputs := make([][]float64, len(inputs))
+	for t, x := range inputs {
+		n := float64(len(x))
+		mean, variance := 0.0, 0.0
+		for i := range x {
+			mean += x[i] / n
+		}
+		for i := range x {
+			variance += (x[i] - mean) * (x[i] - mean) / n
+		}
+		invStd[t] = 1 / math.Sqrt(variance+l.Epsilon)
+
+		normalized[t] = make([]float64, len(x))
+		outputs[t] = make([]float64, len(x))
+		for i := range x {
+			normalized[t][i] = (x[i] - mean) * invStd[t]
+			outputs[t][i] = l.gain[i]*normalized[t][i] + l.bias[i]
+		}
+	}
+	if training {
+		l.normalized, l.invStd = normalized, invStd
+	}
+	return outputs
+}
+
+// Backward implements Layer.
+func (l *LayerNorm) Backward(outputGrads [][]float64) [][]float64 {
+	size := len(l.gain)
+	gainGrads, biasGrads := l.paramGrads[:size], l.paramGrads[size:]
+	inputGrads := make([][]float64, len(outputGrads))
+	for t, dy := range outputGrads {
+		// The mean and variance depend on every feature of the sample:
+		// dx = invStd/n * (n*dxhat - sum(dxhat) - xhat*sum(dxhat*xhat)).
+		xhat := l.normalized[t]
+		dxhat := make([]float64, size)
+		sumGrads, sumProducts := 0.0, 0.0
+		for i := range dy {
+			gainGrads[i] += dy[i] * xhat[i]
+			biasGrads[i] += dy[i]
+			dxhat[i] = dy[i] * l.gain[i]
+			sumGrads += dxhat[i]
+			sumProducts += dxhat[i] * xhat[i]
+		}
+		n := float64(size)
+		inputGrads[t] = make([]float64, size)
+		for i := range dxhat {
+			inputGrads[t][i] = l.invStd[t] / n * (n*dxhat[i] - sumGrads - xhat[i]*sumProducts)
+		}
+	}
+	return inputGrads
+}
+
+// Params implements Layer.
+func (l *LayerNorm) Params() []float64 {
+	return l.params
+}
+
+// Grads implements Layer.
+func (l *LayerNorm) Grads() []float64 {
+	return l.paramGrads
+}
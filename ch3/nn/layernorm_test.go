@@ -0,0 +1,34 @@
+package nn
+
+import "testing"
+
+func TestLayerNorm_Backprop(t *testing.T) {
+	net := NewNetwork()
+	net.SetSeed(1)
+	net.AddLayer(3, 2, ASigmoid, WithInitializer(GlorotUniform))
+	net.AddLayerNorm(3)
+	net.AddLayer(2, 3, ATanh, WithInitializer(GlorotUniform))
+	inputs := []float64{0.5, -1}
+	targets := []float64{1, 0}
+
+	net.backprop([][]float64{inputs}, [][]float64{targets}, MSE{})
+
+	// A single sample is enough, unlike with batch normalization.
+	checkGradients(t, net, func() float64 { return MSE{}.Value(net.FeedForward(inputs), targets) })
+}
+
+func TestLayerNorm_Normalizes(t *testing.T) {
+	l := NewLayerNorm(4)
+	l.Epsilon = 0
+	outputs := l.Forward([][]float64{{1, 2, 3, 4}, {10, 10, 10, 20}}, false)
+	for i, output := range outputs {
+		mean, variance := 0.0, 0.0
+		for _, x := range output {
+			mean += x / 4
+			variance += x * x / 4
+		}
+		if !approxEqual(mean, 0) || !approxEqual(variance, 1) {
+			t.Errorf("Sample %d: expected mean 0 and variance 1, but got %f and %f", i, mean, variance)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package nn
+
+import "math"
+
+// Loss measures how far the outputs of a network are from the targets of a single sample.
+type Loss interface {
+	// Value returns the loss of outputs with respect to targets.
+	Value(outputs, targets []float64) float64
+	// Gradient returns the derivative of the loss with respect to every output.
+	Gradient(outputs, targets []float64) []float64
+}
+
+// epsilon keeps logarithms and divisions of probabilities finite.
+const epsilon = 1e-12
+
+// clip keeps a probability away from 0 and 1.
+func clip(p float64) float64 {
+	return math.Min(math.Max(p, epsilon), 1-epsilon)
+}
+
+// MSE is the mean squared error over the outputs, for regression.
+type MSE struct{}
+
+// Value implements Loss.
+func (MSE) Value(outputs, targets []float64) float64 {
+	result := 0.0
+	for i := range outputs {
+		result += (outputs[i] - targets[i]) * (outputs[i] - targets[i])
+	}
+	return result / float64(len(outputs))
+}
+
+// Gradient implements Loss.
+func (MSE) Gradient(outputs, targets []float64) []float64 {
+	result := make([]float64, len(outputs))
+	for i := range outputs {
+		result[i] = 2 * (outputs[i] - targets[i]) / float64(len(outputs))
+	}
+	return result
+}
+
+// squaredError is half the sum of the squared errors over the outputs. Its gradient is outputs - targets,
+// the step the per-sample Train methods have always taken: it keeps their learning rates meaning the same.
+type squaredError struct{}
+
+// Value implements Loss.
+func (squaredError) Value(outputs, targets []float64) float64 {
+	result := 0.0
+	for i := range outputs {
+		result += 0.5 * (outputs[i] - targets[i]) * (outputs[i] - targets[i])
+	}
+	return result
+}
+
+// Gradient implements Loss.
+func (squaredError) Gradient(outputs, targets []float64) []float64 {
+	result := make([]float64, len(outputs))
+	for i := range outputs {
+		result[i] = outputs[i] - targets[i]
+	}
+	return result
+}
+
+// BinaryCrossEntropy is the cross-entropy of independent yes/no outputs in (0, 1), averaged over the outputs.
+// It is meant to follow sigmoid units.
+type BinaryCrossEntropy struct{}
+
+// Value implements Loss.
+func (BinaryCrossEntropy) Value(outputs, targets []float64) float64 {
+	result := 0.0
+	for i := range outputs {
+		p := clip(outputs[i])
+		result -= targets[i]*math.Log(p) + (1-targets[i])*math.Log(1-p)
+	}
+	return result / float64(len(outputs))
+}
+
+// Gradient implements Loss.
+func (BinaryCrossEntropy) Gradient(outputs, targets []float64) []float64 {
+	result := make([]float64, len(outputs))
+	for i := range outputs {
+		p := clip(outputs[i])
+		result[i] = (p - targets[i]) / (p * (1 - p)) / float64(len(outputs))
+	}
+	return result
+}
+
+// CategoricalCrossEntropy is the cross-entropy between one-hot (or probability) targets and
+// outputs that form a probability distribution over classes.
+type CategoricalCrossEntropy struct{}
+
+// Value implements Loss.
+func (CategoricalCrossEntropy) Value(outputs, targets []float64) float64 {
+	result := 0.0
+	for i := range outputs {
+		if targets[i] != 0 {
+			result -= targets[i] * math.Log(clip(outputs[i]))
+		}
+	}
+	return result
+}
+
+// Gradient implements Loss.
+func (CategoricalCrossEntropy) Gradient(outputs, targets []float64) []float64 {
+	result := make([]float64, len(outputs))
+	for i := range outputs {
+		result[i] = -targets[i] / clip(outputs[i])
+	}
+	return result
+}
+
+// Huber is quadratic for errors smaller than Delta and linear beyond, averaged over the outputs.
+// It is less sensitive to outliers than MSE.
+type Huber struct {
+	Delta float64 // Error at which the loss turns from quadratic to linear. Values of 0 or less mean 1.
+}
+
+func (h Huber) delta() float64 {
+	if h.Delta <= 0 {
+		return 1
+	}
+	return h.Delta
+}
+
+// Value implements Loss.
+func (h Huber) Value(outputs, targets []float64) float64 {
+	delta := h.delta()
+	result := 0.0
+	for i := range outputs {
+		e := math.Abs(outputs[i] - targets[i])
+		if e <= delta {
+			result += 0.5 * e * e
+		} else {
+			result += delta * (e - 0.5*delta)
+		}
+	}
+	return result / float64(len(outputs))
+}
+
+// Gradient implements Loss.
+func (h Huber) Gradient(outputs, targets []float64) []float64 {
+	delta := h.delta()
+	result := make([]float64, len(outputs))
+	for i := range outputs {
+		e := outputs[i] - targets[i]
+		result[i] = math.Max(-delta, math.Min(delta, e)) / float64(len(outputs))
+	}
+	return result
+}
@@ -0,0 +1,42 @@
+package nn
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLoss_Gradient(t *testing.T) {
+	losses := map[string]Loss{
+		"mse":      MSE{},
+		"binary":   BinaryCrossEntropy{},
+		"category": CategoricalCrossEntropy{},
+		"huber":    Huber{Delta: 0.5},
+		"huber-0":  Huber{}, // Delta defaults to 1.
+	}
+	outputs := []float64{0.2, 0.7, 0.1}
+	targets := []float64{0, 1, 0}
+
+	for name, loss := range losses {
+		gradient := loss.Gradient(outputs, targets)
+		for i := range outputs {
+			expected := finiteDifference(&outputs[i], func() float64 { return loss.Value(outputs, targets) })
+			if math.Abs(gradient[i]-expected) > 1e-5 {
+				t.Errorf("%s: expected gradient %f for output %d, but got %f", name, expected, i, gradient[i])
+			}
+		}
+	}
+}
+
+func TestHuber_DefaultDelta(t *testing.T) {
+	outputs, targets := []float64{0.2, 3}, []float64{1, 0}
+	expected, actual := Huber{Delta: 1}.Gradient(outputs, targets), Huber{}.Gradient(outputs, targets)
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("Expected the gradient of Delta 1 %v, but got %v", expected, actual)
+			break
+		}
+	}
+	if v := (Huber{}).Value(outputs, targets); v != (Huber{Delta: 1}).Value(outputs, targets) {
+		t.Errorf("Expected the loss of Delta 1, but got %f", v)
+	}
+}
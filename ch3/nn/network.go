@@ -1,103 +1,492 @@
 package nn
 
+import (
+	"context"
+	"io"
+	"math/rand"
+)
+
 // Network is a muli-layer network of perceptrons.
 type Network struct {
-	layers  []*Layer    // The first layer (layer 0) is the output layer, the last layer is the input layer
-	outputs [][]float64 // Contains L+1, 0..L, entries. The outputs of each layer. The output of layer l is the input of layer l-1. outputs[L] is the input of the network.
+	layers   []Layer         // The first layer (layer 0) is the output layer, the last layer is the input layer. The output of layer l is the input of layer l-1.
+	training bool            // Whether FeedForward runs the layers in training mode, in which dropout is active.
+	seed     int64           // Seed of rng, recorded so that the network can be reproduced.
+	source   *countingSource // Source of rng, which counts draws so that checkpoints can record its state.
+	rng      *rand.Rand      // Source of randomness of the layers of the network.
 }
 
-// NewNetwork creates a new empty network.
+// NewNetwork creates a new empty network with a random seed.
 func NewNetwork() *Network {
-	return &Network{}
+	n := &Network{}
+	n.SetSeed(rand.Int63())
+	return n
+}
+
+// SetSeed resets the source of randomness of the network. Layers added afterwards are initialized
+// from it, so two networks built the same way after the same SetSeed call are identical.
+func (n *Network) SetSeed(seed int64) {
+	n.seed = seed
+	n.source = newCountingSource(seed)
+	n.rng = rand.New(n.source)
+}
+
+// Seed returns the seed last set on the network.
+func (n *Network) Seed() int64 {
+	return n.seed
+}
+
+// SetTraining switches the network between training mode and inference mode, the default.
+// FeedForward, Evaluate and Test follow the mode: in training mode dropout layers drop some of their inputs,
+// in inference mode they do nothing. Training always runs in training mode, whatever the mode of the network.
+func (n *Network) SetTraining(training bool) {
+	n.training = training
+}
+
+// Training reports whether the network is in training mode.
+func (n *Network) Training() bool {
+	return n.training
 }
 
 // AddLayer adds a new layer to the network. The first layer added is the output layer, the last layer added is the input layer.
 // numUnnumOutputsts is the number of neurons, or output units, in the layer.
 // numInputs is the number of inputs to the layer. Also the number of outputs of the previous layer.
 // act is the activation function of the layer.
-func (n *Network) AddLayer(numInputs, numOutputs int, act ActivationType) {
-	n.layers = append(n.layers, NewLayer(numInputs, numOutputs, act))
+// opts configure the layer as in NewDense. Unless WithRand is given, the layer draws from the network's source of randomness.
+func (n *Network) AddLayer(numInputs, numOutputs int, act ActivationType, opts ...LayerOption) {
+	opts = append([]LayerOption{WithRand(n.rng)}, opts...)
+	n.layers = append(n.layers, NewDense(numInputs, numOutputs, act, opts...))
+}
+
+// Add adds any layer to the network, in the same order as AddLayer: the first layer added is the output layer.
+func (n *Network) Add(layer Layer) {
+	n.layers = append(n.layers, layer)
+}
+
+// AddActivation adds an activation layer to the network, in the same order as AddLayer.
+func (n *Network) AddActivation(act ActivationType) {
+	n.layers = append(n.layers, NewActivation(act))
+}
+
+// AddDropout adds a dropout layer to the network, in the same order as AddLayer. The dropout layer drops each output
+// of the layer added next, which feeds it, with probability rate. Its masks are drawn from the network's source of randomness.
+// AddDropout panics if rate is not in [0, 1).
+func (n *Network) AddDropout(rate float64) {
+	n.layers = append(n.layers, NewDropout(rate, n.rng))
 }
 
-// FeedForward calculates the output of the network for a given input.
+// AddBatchNorm adds a batch normalization layer to the network, in the same order as AddLayer.
+// size is the number of outputs of the layer added next, which feeds it.
+func (n *Network) AddBatchNorm(size int) {
+	n.layers = append(n.layers, NewBatchNorm(size))
+}
+
+// AddLayerNorm adds a layer normalization layer to the network, in the same order as AddLayer.
+// size is the number of outputs of the layer added next, which feeds it.
+func (n *Network) AddLayerNorm(size int) {
+	n.layers = append(n.layers, NewLayerNorm(size))
+}
+
+// FeedForward calculates the output of the network for a given input, in the mode set with SetTraining.
 func (n *Network) FeedForward(inputs []float64) []float64 {
-	n.outputs = make([][]float64, len(n.layers)+1)
-	n.outputs[len(n.layers)] = inputs // Dummy entry so that we have the inputs in the same array during backpropagation.
+	return n.forward([][]float64{inputs}, n.training)[0]
+}
+
+// FeedForwardChecked is FeedForward returning a *ShapeError instead of panicking or ignoring inputs
+// when the length of inputs does not match the input layer.
+func (n *Network) FeedForwardChecked(inputs []float64) ([]float64, error) {
+	if err := n.checkSample(inputs, nil); err != nil {
+		return nil, err
+	}
+	return n.FeedForward(inputs), nil
+}
+
+// forward feeds a batch of inputs through all layers, from the input layer to the output layer.
+func (n *Network) forward(inputs [][]float64, training bool) [][]float64 {
 	for i := len(n.layers) - 1; i >= 0; i-- {
-		n.outputs[i] = n.layers[i].FeedForward(inputs)
-		inputs = n.outputs[i]
+		inputs = n.layers[i].Forward(inputs, training)
 	}
-	return n.outputs[0] // First layer is the output layer.
+	return inputs
 }
 
 // Train trains the network on a given input and target using the backpropagation algorithm.
+// The weights are updated right away, which is the same as mini-batch training with a batch size of 1.
+// Train minimizes half the squared error, whose gradient is output - target, so that learningRate keeps the scale
+// it has always had. Fit defaults to MSE instead, which scales that gradient by 2/len(targets).
+// Batch normalization layers need larger batches, use Fit for them.
 // Assumptions:
 //  1. The length of targets is equal to the number of output units in the output layer, which is layer 0.
 //  2. The length of inputs is equal to the number of input units in the input layer, which is the last layer.
 func (n *Network) Train(inputs, targets []float64, learningRate float64) {
-	// Step 1 - Feed forward and compute the output of each layer.
-	n.FeedForward(inputs) // We ignore the output because we already have it in n.outputs.
-
-	// Step 2 - Calculate the deltas of all units of all layers. Starting with the output layer.
-	deltas := make([][]float64, len(n.layers)) // deltas[i][j] is the delta of the jth unit of the ith layer.
-
-	//   Step 2.1 - Calculate the deltas of the output layer.
-	acPrime := ActivationPrimes[n.layers[0].g] // Derivative of the activation function of the output layer.
-	output := n.outputs[0]                     // Output of the output layer (layer 0).
-	deltas[0] = make([]float64, len(output))   // Deltas of the output layer (layer 0).
-	for i := range output {                    // Iterate over all output units.
-		deltas[0][i] = (targets[i] - output[i]) * acPrime(output[i])
-	}
-
-	//   Step 2.2 - Calculate the deltas of the hidden layers.
-	for i := 1; i < len(n.layers); i++ { // Iterate over all hidden layers.
-		acPrime = ActivationPrimes[n.layers[i].g] // Derivative of the activation function of the hidden layer.
-		output = n.outputs[i]                     // Output of the hidden layer.
-		deltas[i] = make([]float64, len(output))  // Deltas of the hidden layer.
-		for j := range output {                   // Iterate over all hidden units.
-			// Calculate the error for each hidden neuron by looking at the errors of the output neurons
-			deltas[i][j] = 0
-			for k := range n.layers[i-1].w { // Iterate over all output units of the layer above.
-				deltas[i][j] += deltas[i-1][k] * n.layers[i-1].w[k][j+1] // TODO: Verify this.
+	n.backprop([][]float64{inputs}, [][]float64{targets}, squaredError{})
+	n.update(SGD{}, learningRate, 1, 0, 0)
+}
+
+// TrainChecked is Train returning a *ShapeError, without training, if inputs or targets do not fit the network.
+func (n *Network) TrainChecked(inputs, targets []float64, learningRate float64) error {
+	if err := n.checkSample(inputs, targets); err != nil {
+		return err
+	}
+	n.Train(inputs, targets, learningRate)
+	return nil
+}
+
+// backprop runs the backpropagation algorithm on a batch of samples, in training mode, and adds the gradient of the loss
+// with respect to every parameter to the gradients accumulated in the layers. It returns the sum of the losses of the samples.
+func (n *Network) backprop(inputs, targets [][]float64, loss Loss) float64 {
+	// Step 1 - Feed forward. Every layer remembers its inputs and outputs.
+	outputs := n.forward(inputs, true)
+
+	// Step 2 - Propagate the derivative of the loss from the output layer back to the input layer.
+	// Each layer accumulates the gradients of its parameters on the way.
+	grads := make([][]float64, len(outputs)) // Derivative of the loss with respect to the outputs of the current layer.
+	start := 0                               // First layer whose Backward is called.
+	if isCategoricalCrossEntropy(loss) && isSoftmax(n.layers[0]) {
+		// Softmax followed by cross-entropy: the derivative with respect to the inputs of the softmax simplifies to
+		// outputs - targets, which stays accurate even when a probability is close to 0.
+		for t := range outputs {
+			grads[t] = make([]float64, len(outputs[t]))
+			for i := range outputs[t] {
+				grads[t][i] = outputs[t][i] - targets[t][i]
 			}
-			deltas[i][j] *= acPrime(output[j])
+		}
+		if dense, ok := n.layers[0].(*Dense); ok {
+			grads = dense.backwardDeltas(grads)
+		}
+		start = 1
+	} else {
+		for t := range outputs {
+			grads[t] = loss.Gradient(outputs[t], targets[t])
+		}
+	}
+	for i := start; i < len(n.layers); i++ {
+		grads = n.layers[i].Backward(grads)
+	}
+
+	total := 0.0
+	for t := range outputs {
+		total += loss.Value(outputs[t], targets[t])
+	}
+	return total
+}
+
+// isSoftmax reports whether layer ends with a softmax.
+func isSoftmax(layer Layer) bool {
+	switch l := layer.(type) {
+	case *Dense:
+		return l.g == ASoftmax
+	case *Activation:
+		return l.Type == ASoftmax
+	}
+	return false
+}
+
+func isCategoricalCrossEntropy(loss Loss) bool {
+	_, ok := loss.(CategoricalCrossEntropy)
+	return ok
+}
+
+// update averages the gradients accumulated over a batch of batchSize samples, adds the gradients of the
+// regularization penalties, clips them as in clipGradients, lets opt update the weights of all layers,
+// applies weight decay and resets the gradients.
+// It returns the global norm of the gradients before clipping and whether they were clipped.
+func (n *Network) update(opt Optimizer, learningRate float64, batchSize int, clipValue, clipNorm float64) (norm float64, clipped bool) {
+	params := make([][]float64, len(n.layers))
+	grads := make([][]float64, len(n.layers))
+	for l, layer := range n.layers {
+		params[l], grads[l] = layer.Params(), layer.Grads()
+		for i := range grads[l] {
+			grads[l][i] /= float64(batchSize)
+		}
+		if r, ok := layer.(penalizer); ok {
+			r.addPenaltyGradients()
+		}
+	}
+
+	norm, clipped = clipGradients(grads, clipValue, clipNorm)
+	opt.Update(params, grads, learningRate)
+	for _, layer := range n.layers {
+		if r, ok := layer.(penalizer); ok {
+			r.decay(learningRate)
+		}
+	}
+
+	for _, g := range grads {
+		clear(g)
+	}
+	return norm, clipped
+}
+
+// TrainOptions configures a training run started with Network.Fit.
+type TrainOptions struct {
+	Epochs       int       // Number of passes over the training data.
+	BatchSize    int       // Number of samples whose gradients are averaged into a single update. 0 uses the whole training set as one batch.
+	LearningRate float64   // Step size of each update, unless Schedule is set.
+	Schedule     Schedule  // If set, gives the learning rate of every update.
+	Optimizer    Optimizer // Algorithm that turns gradients into weight updates. nil means plain SGD. Optimizers keep state, so do not share one between networks.
+	Loss         Loss      // Loss minimized by the training. nil means MSE.
+	Log          io.Writer // If set, the metrics of every epoch are written to it. Shorthand for a Logger callback.
+	Shuffle      bool      // Reshuffle the order of the samples at the beginning of every epoch.
+	Seed         int64     // Seed of the shuffle, so that runs with the same options visit the samples in the same order.
+	ClipValue    float64   // If positive, every element of the averaged gradients is clipped to [-ClipValue, ClipValue].
+	ClipNorm     float64   // If positive, the averaged gradients of all layers are scaled down to a global L2 norm of at most ClipNorm.
+
+	CheckpointPath  string // If set, the state of training is saved to this file every CheckpointEvery epochs and at the end of training, to continue with Resume.
+	CheckpointEvery int    // Number of epochs between checkpoints. 0 means every epoch.
+
+	ValInputs     [][]float64    // Optional validation data, evaluated at the end of every epoch as "val_loss" and "val_accuracy".
+	ValTargets    [][]float64    // Targets of ValInputs.
+	EarlyStopping *EarlyStopping // If set, stops training once the monitored metric stops improving. Runs before Callbacks.
+	Callbacks     []Callback     // Notified of the progress of training, in order.
+}
+
+// Fit trains the network with mini-batch gradient descent. The gradients of the samples of a batch
+// are averaged before the weights are updated. The last batch of an epoch may be smaller.
+// It returns the history of the metrics of every epoch. Fit ignores errors: use FitContext to learn
+// whether the options are valid and checkpoints were written.
+func (n *Network) Fit(inputs, targets [][]float64, opts TrainOptions) *History {
+	history, _ := n.FitContext(context.Background(), inputs, targets, opts)
+	return history
+}
+
+// FitContext is Fit stopping early when ctx is done. ctx is checked before every batch, so the network
+// is never left halfway through an update: it holds the weights of the last complete batch and can be
+// saved or trained further. The interrupted epoch is not recorded in the history, but OnTrainEnd is
+// still called. FitContext returns ctx.Err() if training was interrupted, or the error of a checkpoint
+// that could not be written, which also stops training. It returns an error without training if
+// opts.EarlyStopping monitors a metric that is not computed, such as "val_loss" without ValInputs.
+func (n *Network) FitContext(ctx context.Context, inputs, targets [][]float64, opts TrainOptions) (*History, error) {
+	return n.fit(ctx, inputs, targets, opts, nil)
+}
+
+// fit implements FitContext and Resume. If resume is set, training continues from it.
+func (n *Network) fit(ctx context.Context, inputs, targets [][]float64, opts TrainOptions, resume *checkpoint) (*History, error) {
+	if opts.EarlyStopping != nil {
+		if err := opts.EarlyStopping.check(&opts); err != nil {
+			return &History{}, err
+		}
+	}
+	batchSize := opts.BatchSize
+	if batchSize < 1 || batchSize > len(inputs) {
+		batchSize = len(inputs)
+	}
+	order := make([]int, len(inputs))
+	for i := range order {
+		order[i] = i
+	}
+	rng := rand.New(rand.NewSource(opts.Seed))
+	opt := opts.Optimizer
+	if opt == nil {
+		opt = SGD{}
+	}
+	loss := opts.Loss
+	if loss == nil {
+		loss = MSE{}
+	}
+	history := &History{}
+	p := &Progress{Network: n, Epochs: opts.Epochs, LearningRate: opts.LearningRate}
+	if resume != nil {
+		if err := resume.restore(n, &opt, &opts, p, history); err != nil {
+			return nil, err
+		}
+	}
+	start := p.Epoch // First epoch to train. The shuffles of the epochs before it are replayed.
+	every := opts.CheckpointEvery
+	if every < 1 {
+		every = 1
+	}
+
+	callbacks := []Callback{&historyRecorder{history: history}}
+	if opts.EarlyStopping != nil {
+		callbacks = append(callbacks, opts.EarlyStopping)
+	}
+	if c, ok := opts.Schedule.(Callback); ok {
+		callbacks = append(callbacks, c)
+	}
+	if opts.Log != nil {
+		callbacks = append(callbacks, &Logger{W: opts.Log})
+	}
+	callbacks = append(callbacks, opts.Callbacks...)
+
+	for _, c := range callbacks {
+		c.OnTrainBegin(p)
+	}
+	if resume != nil {
+		resume.restoreEarlyStopping(opts.EarlyStopping)
+	}
+	if opts.Shuffle {
+		for epoch := 0; epoch < start; epoch++ {
+			rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
 		}
 	}
+	var err error
+	for epoch := start; epoch < opts.Epochs && !p.stop; epoch++ {
+		p.Epoch = epoch
+		for _, c := range callbacks {
+			c.OnEpochBegin(p)
+		}
+		if p.stop {
+			break
+		}
+		if opts.Shuffle {
+			rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		}
 
-	// Step 3 - Update the weights of all layers.
-	for l := range n.layers { // Iterate over all layers.
-		w := n.layers[l].w // Weights of the layer.
-		for j := range w { // Iterate over all units of the layer.
-			factor := learningRate * deltas[l][j]
-			w[j][0] += factor         // Adjust bias.
-			for k := range w[j][1:] { // Adjust weights of all inputs.
-				w[j][k+1] += factor * n.outputs[l+1][j] // outputs[l+1] is the input to layer l.
+		total, count, clipped := 0.0, 0, 0
+		for start := 0; start < len(order) && !p.stop; start += batchSize {
+			if err = ctx.Err(); err != nil {
+				break
+			}
+			batch := order[start:min(start+batchSize, len(order))]
+			batchLoss := n.penalty() * float64(len(batch)) // The penalty counts once per sample, as the batch loss is a mean.
+			batchInputs, batchTargets := make([][]float64, len(batch)), make([][]float64, len(batch))
+			for i, t := range batch {
+				batchInputs[i], batchTargets[i] = inputs[t], targets[t]
+			}
+			batchLoss += n.backprop(batchInputs, batchTargets, loss)
+			if opts.Schedule != nil {
+				p.LearningRate = opts.Schedule.Rate(p.Epoch, p.Step)
+			}
+			p.GradNorm, p.Clipped = n.update(opt, p.LearningRate, len(batch), opts.ClipValue, opts.ClipNorm)
+			if p.Clipped {
+				clipped++
+			}
+			p.Step++
+
+			total += batchLoss
+			count += len(batch)
+			p.Batch = start / batchSize
+			p.Loss = batchLoss / float64(len(batch))
+			for _, c := range callbacks {
+				c.OnBatchEnd(p)
+			}
+		}
+		if err != nil {
+			break
+		}
+
+		// Compute the metrics of the epoch. The training loss is the mean over the epoch, while the weights were changing.
+		p.Loss = total / float64(count)
+		p.Metrics = map[string]float64{"loss": p.Loss}
+		if opts.ClipValue > 0 || opts.ClipNorm > 0 {
+			p.Metrics["clipped"] = float64(clipped)
+		}
+		if opts.ValInputs != nil {
+			training := n.training
+			n.training = false // Validate in inference mode.
+			p.Metrics["val_loss"] = n.Evaluate(opts.ValInputs, opts.ValTargets, loss)
+			p.Metrics["val_accuracy"] = n.Test(opts.ValInputs, opts.ValTargets)
+			n.training = training
+		}
+		for _, c := range callbacks {
+			c.OnEpochEnd(p)
+		}
+
+		if opts.CheckpointPath != "" && ((epoch+1)%every == 0 || epoch == opts.Epochs-1 || p.stop) {
+			var c *checkpoint
+			if c, err = n.newCheckpoint(p, opt, &opts, history); err == nil {
+				err = c.save(opts.CheckpointPath)
+			}
+			if err != nil {
+				break
 			}
 		}
 	}
+	for _, c := range callbacks {
+		c.OnTrainEnd(p)
+	}
+	return history, err
 }
 
-// TrainAll trains the network on a given set of training data.
-func (n *Network) TrainAll(inputs, targets [][]float64, epochs int, learningRate float64) {
-	for i := 0; i < epochs; i++ {
-		for t := range inputs {
-			n.Train(inputs[t], targets[t], learningRate)
+// weights returns a copy of the weights of all layers, followed by their state if they have some.
+func (n *Network) weights() [][]float64 {
+	result := make([][]float64, len(n.layers))
+	for l, layer := range n.layers {
+		params := layer.Params()
+		result[l] = append([]float64{}, params...)
+		if s, ok := layer.(Stateful); ok {
+			result[l] = append(result[l], s.State()...)
 		}
 	}
+	return result
 }
 
-// Test calculates the accuracy of the network for a given set of test data.
+// setWeights overwrites the weights and state of all layers with a copy returned by weights.
+func (n *Network) setWeights(weights [][]float64) {
+	for l, layer := range n.layers {
+		params := layer.Params()
+		copy(params, weights[l])
+		if s, ok := layer.(Stateful); ok {
+			copy(s.State(), weights[l][len(params):])
+		}
+	}
+}
+
+// Evaluate calculates the mean loss of the network over a given set of data, fed as a single batch in the mode set with SetTraining,
+// including the regularization penalties of its layers.
+func (n *Network) Evaluate(inputs, targets [][]float64, loss Loss) float64 {
+	outputs := n.forward(inputs, n.training)
+	total := 0.0
+	for t := range outputs {
+		total += loss.Value(outputs[t], targets[t])
+	}
+	return total/float64(len(inputs)) + n.penalty()
+}
+
+// penalty returns the sum of the regularization penalties of all layers.
+func (n *Network) penalty() float64 {
+	result := 0.0
+	for _, layer := range n.layers {
+		if r, ok := layer.(penalizer); ok {
+			result += r.penalty()
+		}
+	}
+	return result
+}
+
+// TrainAll trains the network on a given set of training data, one sample at a time and in the given order.
+// Like Train, it minimizes half the squared error, which is the loss recorded in the history.
+func (n *Network) TrainAll(inputs, targets [][]float64, epochs int, learningRate float64) *History {
+	return n.Fit(inputs, targets, TrainOptions{Epochs: epochs, BatchSize: 1, LearningRate: learningRate, Loss: squaredError{}})
+}
+
+// TrainAllContext is TrainAll stopping early when ctx is done, as in FitContext.
+func (n *Network) TrainAllContext(ctx context.Context, inputs, targets [][]float64, epochs int, learningRate float64) (*History, error) {
+	return n.FitContext(ctx, inputs, targets, TrainOptions{Epochs: epochs, BatchSize: 1, LearningRate: learningRate, Loss: squaredError{}})
+}
+
+// TrainAllChecked is TrainAll returning a *ShapeError, without training, if a sample does not fit the network.
+func (n *Network) TrainAllChecked(inputs, targets [][]float64, epochs int, learningRate float64) (*History, error) {
+	if err := n.checkData(inputs, targets); err != nil {
+		return nil, err
+	}
+	return n.TrainAll(inputs, targets, epochs, learningRate), nil
+}
+
+// Test calculates the accuracy of the network for a given set of test data, fed as a single batch in the mode set with SetTraining.
+// With several output units, such as a softmax layer, the predicted class is the unit with the largest output.
+// With a single output unit, the prediction is positive when the output is at least 0.5.
 func (n *Network) Test(inputs, targets [][]float64) float64 {
 	numCorrect := 0
-	for t := range inputs {
-		outputs := n.FeedForward(inputs[t])
-		if maxIndex(outputs) == maxIndex(targets[t]) {
+	for t, outputs := range n.forward(inputs, n.training) {
+		if len(outputs) == 1 {
+			if (outputs[0] >= 0.5) == (targets[t][0] >= 0.5) {
+				numCorrect++
+			}
+		} else if maxIndex(outputs) == maxIndex(targets[t]) {
 			numCorrect++
 		}
 	}
 	return float64(numCorrect) / float64(len(inputs))
 }
 
+// TestChecked is Test returning a *ShapeError if a sample does not fit the network.
+func (n *Network) TestChecked(inputs, targets [][]float64) (float64, error) {
+	if err := n.checkData(inputs, targets); err != nil {
+		return 0, err
+	}
+	return n.Test(inputs, targets), nil
+}
+
 func maxIndex(xs []float64) int {
 	maxIndex := 0
 	for i := range xs {
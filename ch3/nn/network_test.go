@@ -0,0 +1,128 @@
+package nn
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestNetwork_Backprop(t *testing.T) {
+	tests := []struct {
+		name   string
+		output ActivationType
+		loss   Loss
+	}{
+		{"sigmoid-mse", ASigmoid, MSE{}},
+		{"softmax-mse", ASoftmax, MSE{}},
+		{"softmax-crossentropy", ASoftmax, CategoricalCrossEntropy{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net := NewNetwork()
+			net.AddLayer(3, 2, tt.output)
+			net.AddLayer(2, 3, ATanh)
+			inputs := []float64{0.5, -1}
+			targets := []float64{1, 0}
+
+			net.backprop([][]float64{inputs}, [][]float64{targets}, tt.loss)
+			checkGradients(t, net, func() float64 { return tt.loss.Value(net.FeedForward(inputs), targets) })
+		})
+	}
+}
+
+func TestNetwork_Fit(t *testing.T) {
+	inputs := [][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}}
+	targets := [][]float64{{0}, {1}, {1}, {1}}
+
+	net := NewNetwork()
+	net.AddLayer(2, 1, ASigmoid)
+	net.Fit(inputs, targets, TrainOptions{Epochs: 2000, BatchSize: 2, LearningRate: 1, Shuffle: true, Seed: 1})
+
+	for i := range inputs {
+		if output := net.FeedForward(inputs[i]); math.Abs(output[0]-targets[i][0]) > 0.2 {
+			t.Errorf("Expected %v for %v, but got %v", targets[i], inputs[i], output)
+		}
+	}
+}
+
+func TestNetwork_SetSeed(t *testing.T) {
+	inputs := [][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}}
+	targets := [][]float64{{0}, {1}, {1}, {0}}
+	build := func() *Network {
+		net := NewNetwork()
+		net.SetSeed(7)
+		net.AddLayer(3, 1, ASigmoid, WithInitializer(GlorotUniform))
+		net.AddLayer(2, 3, ATanh, WithInitializer(GlorotNormal))
+		net.Fit(inputs, targets, TrainOptions{Epochs: 10, BatchSize: 2, LearningRate: 0.5, Shuffle: true, Seed: 3})
+		return net
+	}
+
+	// Two runs with the same seeds produce bit-for-bit identical networks.
+	a, b := build(), build()
+	aw, bw := a.weights(), b.weights()
+	for l := range aw {
+		for i := range aw[l] {
+			if aw[l][i] != bw[l][i] {
+				t.Fatalf("Layer %d: expected identical weights, but got %v and %v", l, aw[l], bw[l])
+			}
+		}
+	}
+	if a.Seed() != 7 {
+		t.Errorf("Expected seed 7, but got %d", a.Seed())
+	}
+}
+
+func TestNetwork_FitContext(t *testing.T) {
+	inputs := [][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}}
+	targets := [][]float64{{0}, {1}, {1}, {1}}
+	net := NewNetwork()
+	net.AddLayer(2, 1, ASigmoid)
+
+	// Cancel in the middle of the second epoch.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	steps := 0
+	cancelAt := CallbackFuncs{BatchEnd: func(p *Progress) {
+		steps = p.Step
+		if p.Step == 6 {
+			cancel()
+		}
+	}}
+	history, err := net.FitContext(ctx, inputs, targets, TrainOptions{Epochs: 10, BatchSize: 1, LearningRate: 0.1, Callbacks: []Callback{cancelAt}})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, but got %v", err)
+	}
+	if steps != 6 {
+		t.Errorf("Expected training to stop after 6 updates, but got %d", steps)
+	}
+	if len(history.Epochs) != 1 {
+		t.Errorf("Expected only the first epoch in the history, but got %d", len(history.Epochs))
+	}
+	for _, layer := range net.layers {
+		for _, g := range layer.Grads() {
+			if g != 0 {
+				t.Fatalf("Expected no gradients left from an interrupted batch, but got %v", layer.Grads())
+			}
+		}
+	}
+}
+
+func TestNetwork_TrainStep(t *testing.T) {
+	net := NewNetwork()
+	net.AddLayer(1, 3, ASigmoid, WithInitializer(Constant(0.1)), WithBiasInitializer(Constant(0.2)))
+	inputs, targets := []float64{1}, []float64{0, 1, 0}
+	outputs := net.FeedForward(inputs)
+	before := append([]float64{}, net.layers[0].Params()...)
+
+	// Every weight moves by learningRate * (target - output) * output * (1 - output) * input, whatever the number of outputs.
+	net.Train(inputs, targets, 0.5)
+	params := net.layers[0].Params()
+	for i, o := range outputs {
+		expected := 0.5 * (targets[i] - o) * o * (1 - o)
+		if step := params[2*i] - before[2*i]; !approxEqual(step, expected) {
+			t.Errorf("Unit %d: expected a bias step of %f, but got %f", i, expected, step)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package nn
+
+import "math"
+
+// Optimizer turns the gradients of the parameters of a network into updates of those parameters.
+type Optimizer interface {
+	// Update moves every element of params against the matching element of grads.
+	// params[i] and grads[i] have the same length and refer to the same tensor on every call,
+	// so implementations can keep state for each parameter by position.
+	Update(params, grads [][]float64, learningRate float64)
+}
+
+// zerosLike returns a zeroed copy of the shape of xs.
+func zerosLike(xs [][]float64) [][]float64 {
+	result := make([][]float64, len(xs))
+	for i := range xs {
+		result[i] = make([]float64, len(xs[i]))
+	}
+	return result
+}
+
+// SGD is plain stochastic gradient descent: w -= learningRate * g.
+type SGD struct{}
+
+// Update implements Optimizer.
+func (SGD) Update(params, grads [][]float64, learningRate float64) {
+	for i := range params {
+		for j := range params[i] {
+			params[i][j] -= learningRate * grads[i][j]
+		}
+	}
+}
+
+// Momentum is SGD with momentum. Velocity accumulates past gradients: v = Beta * v + g, w -= learningRate * v.
+type Momentum struct {
+	Beta     float64     // Fraction of the velocity kept from one step to the next, typically 0.9.
+	Velocity [][]float64 // State, one vector per parameter tensor.
+}
+
+// NewMomentum creates an SGD optimizer with momentum beta.
+func NewMomentum(beta float64) *Momentum {
+	return &Momentum{Beta: beta}
+}
+
+// Update implements Optimizer.
+func (o *Momentum) Update(params, grads [][]float64, learningRate float64) {
+	if o.Velocity == nil {
+		o.Velocity = zerosLike(params)
+	}
+	for i := range params {
+		for j := range params[i] {
+			o.Velocity[i][j] = o.Beta*o.Velocity[i][j] + grads[i][j]
+			params[i][j] -= learningRate * o.Velocity[i][j]
+		}
+	}
+}
+
+// Nesterov is SGD with Nesterov momentum. It looks ahead along the velocity: w -= learningRate * (g + Beta * v).
+type Nesterov struct {
+	Beta     float64     // Fraction of the velocity kept from one step to the next, typically 0.9.
+	Velocity [][]float64 // State, one vector per parameter tensor.
+}
+
+// NewNesterov creates an SGD optimizer with Nesterov momentum beta.
+func NewNesterov(beta float64) *Nesterov {
+	return &Nesterov{Beta: beta}
+}
+
+// Update implements Optimizer.
+func (o *Nesterov) Update(params, grads [][]float64, learningRate float64) {
+	if o.Velocity == nil {
+		o.Velocity = zerosLike(params)
+	}
+	for i := range params {
+		for j := range params[i] {
+			o.Velocity[i][j] = o.Beta*o.Velocity[i][j] + grads[i][j]
+			params[i][j] -= learningRate * (grads[i][j] + o.Beta*o.Velocity[i][j])
+		}
+	}
+}
+
+// Adagrad scales the step of every parameter by the inverse root of the sum of its squared gradients.
+type Adagrad struct {
+	Epsilon float64     // Added to the denominator for numerical stability.
+	Cache   [][]float64 // State: sum of the squared gradients.
+}
+
+// NewAdagrad creates an Adagrad optimizer.
+func NewAdagrad() *Adagrad {
+	return &Adagrad{Epsilon: 1e-8}
+}
+
+// Update implements Optimizer.
+func (o *Adagrad) Update(params, grads [][]float64, learningRate float64) {
+	if o.Cache == nil {
+		o.Cache = zerosLike(params)
+	}
+	for i := range params {
+		for j := range params[i] {
+			o.Cache[i][j] += grads[i][j] * grads[i][j]
+			params[i][j] -= learningRate * grads[i][j] / (math.Sqrt(o.Cache[i][j]) + o.Epsilon)
+		}
+	}
+}
+
+// RMSProp scales the step of every parameter by the inverse root of a moving average of its squared gradients.
+type RMSProp struct {
+	Rho     float64     // Decay rate of the moving average, typically 0.9.
+	Epsilon float64     // Added to the denominator for numerical stability.
+	Cache   [][]float64 // State: moving average of the squared gradients.
+}
+
+// NewRMSProp creates an RMSProp optimizer with decay rate rho.
+func NewRMSProp(rho float64) *RMSProp {
+	return &RMSProp{Rho: rho, Epsilon: 1e-8}
+}
+
+// Update implements Optimizer.
+func (o *RMSProp) Update(params, grads [][]float64, learningRate float64) {
+	if o.Cache == nil {
+		o.Cache = zerosLike(params)
+	}
+	for i := range params {
+		for j := range params[i] {
+			o.Cache[i][j] = o.Rho*o.Cache[i][j] + (1-o.Rho)*grads[i][j]*grads[i][j]
+			params[i][j] -= learningRate * grads[i][j] / (math.Sqrt(o.Cache[i][j]) + o.Epsilon)
+		}
+	}
+}
+
+// Adam keeps bias-corrected moving averages of the gradients and of their squares.
+type Adam struct {
+	Beta1   float64     // Decay rate of the first moment, typically 0.9.
+	Beta2   float64     // Decay rate of the second moment, typically 0.999.
+	Epsilon float64     // Added to the denominator for numerical stability.
+	M       [][]float64 // State: first moment.
+	V       [][]float64 // State: second moment.
+	T       int         // State: number of updates so far.
+}
+
+// NewAdam creates an Adam optimizer with the usual defaults.
+func NewAdam() *Adam {
+	return &Adam{Beta1: 0.9, Beta2: 0.999, Epsilon: 1e-8}
+}
+
+// Update implements Optimizer.
+func (o *Adam) Update(params, grads [][]float64, learningRate float64) {
+	if o.M == nil {
+		o.M = zerosLike(params)
+		o.V = zerosLike(params)
+	}
+	o.T++
+	c1 := 1 - math.Pow(o.Beta1, float64(o.T)) // Bias corrections.
+	c2 := 1 - math.Pow(o.Beta2, float64(o.T))
+	for i := range params {
+		for j := range params[i] {
+			g := grads[i][j]
+			o.M[i][j] = o.Beta1*o.M[i][j] + (1-o.Beta1)*g
+			o.V[i][j] = o.Beta2*o.V[i][j] + (1-o.Beta2)*g*g
+			params[i][j] -= learningRate * (o.M[i][j] / c1) / (math.Sqrt(o.V[i][j]/c2) + o.Epsilon)
+		}
+	}
+}
+
+// AdamW is Adam with decoupled weight decay: the weights shrink towards zero independently of the gradients.
+type AdamW struct {
+	Adam
+	WeightDecay float64 // Fraction of every weight removed per update, scaled by the learning rate.
+}
+
+// NewAdamW creates an AdamW optimizer with weight decay.
+func NewAdamW(weightDecay float64) *AdamW {
+	return &AdamW{Adam: *NewAdam(), WeightDecay: weightDecay}
+}
+
+// Update implements Optimizer.
+func (o *AdamW) Update(params, grads [][]float64, learningRate float64) {
+	for i := range params {
+		for j := range params[i] {
+			params[i][j] -= learningRate * o.WeightDecay * params[i][j]
+		}
+	}
+	o.Adam.Update(params, grads, learningRate)
+}
@@ -0,0 +1,42 @@
+package nn
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOptimizers(t *testing.T) {
+	optimizers := map[string]Optimizer{
+		"sgd":      SGD{},
+		"momentum": NewMomentum(0.9),
+		"nesterov": NewNesterov(0.9),
+		"adagrad":  NewAdagrad(),
+		"rmsprop":  NewRMSProp(0.9),
+		"adam":     NewAdam(),
+		"adamw":    NewAdamW(0.001),
+	}
+	for name, opt := range optimizers {
+		// Minimize (w - 3)^2 for two independent parameter tensors.
+		params := [][]float64{{0, 10}, {-5}}
+		grads := zerosLike(params)
+		for step := 0; step < 2000; step++ {
+			for i := range params {
+				for j := range params[i] {
+					grads[i][j] = 2 * (params[i][j] - 3)
+				}
+			}
+			learningRate := 0.05
+			if name == "adagrad" { // Adagrad steps shrink quickly, so it needs a larger rate.
+				learningRate = 1
+			}
+			opt.Update(params, grads, learningRate)
+		}
+		for i := range params {
+			for j := range params[i] {
+				if math.Abs(params[i][j]-3) > 0.05 {
+					t.Errorf("%s: expected parameter close to 3, but got %f", name, params[i][j])
+				}
+			}
+		}
+	}
+}
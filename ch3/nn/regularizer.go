@@ -0,0 +1,79 @@
+package nn
+
+import "math"
+
+// Regularizer penalizes the weights of a layer to limit overfitting.
+// The biases, the first column of the weights, are left alone unless Bias is set.
+type Regularizer struct {
+	L1          float64 // Factor of the sum of the absolute weights added to the loss.
+	L2          float64 // Factor of the sum of the squared weights added to the loss.
+	WeightDecay float64 // Fraction of every weight removed after each update, scaled by the learning rate. Decoupled from the gradients, as in AdamW.
+	Bias        bool    // Also regularize the biases.
+}
+
+// WithRegularizer sets the regularizer of a layer. By default layers are not regularized.
+func WithRegularizer(r Regularizer) LayerOption {
+	return func(c *layerConfig) { c.reg = r }
+}
+
+// penalizer is implemented by the layers of a network that have a Regularizer.
+type penalizer interface {
+	penalty() float64
+	addPenaltyGradients()
+	decay(learningRate float64)
+}
+
+// regularized reports whether the jth weight of a row is subject to regularization.
+func (r Regularizer) regularized(j int) bool {
+	return j > 0 || r.Bias
+}
+
+// penalty returns the L1 and L2 penalty of the weights of the layer.
+func (p *Dense) penalty() float64 {
+	if p.reg.L1 == 0 && p.reg.L2 == 0 {
+		return 0
+	}
+	result := 0.0
+	for i := range p.w {
+		for j, w := range p.w[i] {
+			if p.reg.regularized(j) {
+				result += p.reg.L1*math.Abs(w) + p.reg.L2*w*w
+			}
+		}
+	}
+	return result
+}
+
+// addPenaltyGradients adds the gradient of the penalty to the gradients of the layer.
+func (p *Dense) addPenaltyGradients() {
+	if p.reg.L1 == 0 && p.reg.L2 == 0 {
+		return
+	}
+	for i := range p.w {
+		for j, w := range p.w[i] {
+			if p.reg.regularized(j) {
+				sign := 0.0
+				if w > 0 {
+					sign = 1
+				} else if w < 0 {
+					sign = -1
+				}
+				p.grads[i][j] += p.reg.L1*sign + 2*p.reg.L2*w
+			}
+		}
+	}
+}
+
+// decay shrinks the weights of the layer by its decoupled weight decay.
+func (p *Dense) decay(learningRate float64) {
+	if p.reg.WeightDecay == 0 {
+		return
+	}
+	for i := range p.w {
+		for j := range p.w[i] {
+			if p.reg.regularized(j) {
+				p.w[i][j] -= learningRate * p.reg.WeightDecay * p.w[i][j]
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package nn
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRegularizer(t *testing.T) {
+	layer := NewDense(2, 2, ASigmoid, WithRegularizer(Regularizer{L1: 0.1, L2: 0.5}), WithInitializer(GlorotNormal))
+
+	// The gradient of the penalty matches a central finite difference, and biases are excluded.
+	layer.addPenaltyGradients()
+	for i := range layer.w {
+		if layer.grads[i][0] != 0 {
+			t.Errorf("Expected no penalty on bias %d, but got gradient %f", i, layer.grads[i][0])
+		}
+		for j := range layer.w[i] {
+			if expected := finiteDifference(&layer.w[i][j], layer.penalty); math.Abs(layer.grads[i][j]-expected) > 1e-6 {
+				t.Errorf("Weight [%d][%d]: expected gradient %f, but got %f", i, j, expected, layer.grads[i][j])
+			}
+		}
+	}
+
+	// Weight decay shrinks the weights but not the biases.
+	layer = NewDense(1, 1, ASigmoid, WithRegularizer(Regularizer{WeightDecay: 0.5}), WithInitializer(Constant(2)), WithBiasInitializer(Constant(2)))
+	layer.decay(0.1)
+	if layer.w[0][0] != 2 || !approxEqual(layer.w[0][1], 1.9) {
+		t.Errorf("Expected weights [2 1.9], but got %v", layer.w[0])
+	}
+}
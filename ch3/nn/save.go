@@ -0,0 +1,400 @@
+package nn
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// FormatVersion is the version of the formats written by Save, SaveJSON and SaveBinary.
+// Every version up to FormatVersion can be loaded.
+const FormatVersion = 1
+
+// binaryMagic starts every file written by SaveBinary.
+const binaryMagic = "GONN"
+
+// VersionError reports a saved network or a checkpoint whose version this package cannot read,
+// typically because it was written by a newer version of the package.
+type VersionError struct {
+	What    string // What has an unsupported version: "format" for saved networks, "checkpoint" for checkpoints.
+	Version int    // Version read.
+	Max     int    // Latest version this package can read.
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("nn: unsupported %s version %d, expected 1 to %d", e.What, e.Version, e.Max)
+}
+
+// savedNetwork is the architecture and weights of a network, as saved in both formats.
+type savedNetwork struct {
+	Version int          `json:"version"`
+	Seed    int64        `json:"seed"`
+	Layers  []savedLayer `json:"layers"` // From the input layer to the output layer.
+}
+
+// savedLayer is a layer of a savedNetwork. Which fields are set depends on Type.
+type savedLayer struct {
+	Type        string      `json:"type"`                   // "dense", "activation", "dropout", "batchnorm" or "layernorm".
+	Activation  string      `json:"activation,omitempty"`   // Dense and activation layers.
+	Inputs      int         `json:"inputs,omitempty"`       // Number of inputs of dense and normalization layers.
+	Outputs     int         `json:"outputs,omitempty"`      // Number of outputs of dense layers.
+	Rate        float64     `json:"rate,omitempty"`         // Dropout rate.
+	Momentum    float64     `json:"momentum,omitempty"`     // Momentum of batch normalization.
+	Epsilon     float64     `json:"epsilon,omitempty"`      // Epsilon of normalization layers.
+	Weights     [][]float64 `json:"weights,omitempty"`      // Weights of dense layers, one row per output unit, without the bias.
+	Biases      []float64   `json:"biases,omitempty"`       // Biases of dense and normalization layers.
+	Gains       []float64   `json:"gains,omitempty"`        // Gains of normalization layers.
+	RunningMean []float64   `json:"running_mean,omitempty"` // Running mean of batch normalization.
+	RunningVar  []float64   `json:"running_var,omitempty"`  // Running variance of batch normalization.
+}
+
+var activationNames = map[ActivationType]string{
+	AStep:    "step",
+	ASigmoid: "sigmoid",
+	AReLU:    "relu",
+	ATanh:    "tanh",
+	ASoftmax: "softmax",
+}
+
+func activationByName(name string) (ActivationType, error) {
+	for act, n := range activationNames {
+		if n == name {
+			return act, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown activation %q", name)
+}
+
+// saved converts the network to its saved form. Only the layers of this package can be saved.
+// Regularizers are part of training, not of the model, and are not saved.
+func (n *Network) saved() (*savedNetwork, error) {
+	s := &savedNetwork{Version: FormatVersion, Seed: n.seed}
+	for i := len(n.layers) - 1; i >= 0; i-- {
+		var l savedLayer
+		switch layer := n.layers[i].(type) {
+		case *Dense:
+			l = savedLayer{Type: "dense", Activation: activationNames[layer.g], Inputs: layer.NumInputs(), Outputs: layer.NumOutputs()}
+			for _, row := range layer.w {
+				l.Biases = append(l.Biases, row[0])
+				l.Weights = append(l.Weights, append([]float64{}, row[1:]...))
+			}
+		case *Activation:
+			l = savedLayer{Type: "activation", Activation: activationNames[layer.Type]}
+		case *Dropout:
+			l = savedLayer{Type: "dropout", Rate: layer.Rate}
+		case *BatchNorm:
+			l = savedLayer{Type: "batchnorm", Inputs: layer.NumInputs(), Momentum: layer.Momentum, Epsilon: layer.Epsilon,
+				Gains: layer.gamma, Biases: layer.beta, RunningMean: layer.RunningMean, RunningVar: layer.RunningVar}
+		case *LayerNorm:
+			l = savedLayer{Type: "layernorm", Inputs: layer.NumInputs(), Epsilon: layer.Epsilon, Gains: layer.gain, Biases: layer.bias}
+		default:
+			return nil, fmt.Errorf("nn: cannot save layer %d of type %T", i, layer)
+		}
+		s.Layers = append(s.Layers, l)
+	}
+	return s, nil
+}
+
+// network rebuilds the network saved in s.
+func (s *savedNetwork) network() (*Network, error) {
+	if s.Version < 1 || s.Version > FormatVersion {
+		return nil, &VersionError{What: "format", Version: s.Version, Max: FormatVersion}
+	}
+	n := NewNetwork()
+	n.SetSeed(s.Seed)
+	for i := len(s.Layers) - 1; i >= 0; i-- {
+		layer, err := s.Layers[i].layer(n)
+		if err != nil {
+			// Report the index of the layer the way Network does, counting from the output layer.
+			return nil, fmt.Errorf("nn: layer %d: %w", len(s.Layers)-1-i, err)
+		}
+		n.Add(layer)
+	}
+	return n, nil
+}
+
+// layer rebuilds the saved layer for network n.
+func (l *savedLayer) layer(n *Network) (Layer, error) {
+	checkLen := func(name string, xs []float64, expected int) error {
+		if len(xs) != expected {
+			return fmt.Errorf("expected %d %s, got %d", expected, name, len(xs))
+		}
+		return nil
+	}
+	if l.Inputs < 0 || l.Outputs < 0 {
+		return nil, fmt.Errorf("negative size: %d inputs, %d outputs", l.Inputs, l.Outputs)
+	}
+	switch l.Type {
+	case "dense":
+		act, err := activationByName(l.Activation)
+		if err != nil {
+			return nil, err
+		}
+		if len(l.Weights) != l.Outputs {
+			return nil, fmt.Errorf("expected %d rows of weights, got %d", l.Outputs, len(l.Weights))
+		}
+		if err := checkLen("biases", l.Biases, l.Outputs); err != nil {
+			return nil, err
+		}
+		// Check the weights before allocating the layer, so that its size is bounded by the data read.
+		for _, row := range l.Weights {
+			if err := checkLen("weights", row, l.Inputs); err != nil {
+				return nil, err
+			}
+		}
+		layer := NewDense(l.Inputs, l.Outputs, act, WithInitializer(Zeros), WithBiasInitializer(Zeros))
+		for i, row := range l.Weights {
+			layer.w[i][0] = l.Biases[i]
+			copy(layer.w[i][1:], row)
+		}
+		return layer, nil
+	case "activation":
+		act, err := activationByName(l.Activation)
+		if err != nil {
+			return nil, err
+		}
+		return NewActivation(act), nil
+	case "dropout":
+		if !validRate(l.Rate) {
+			return nil, fmt.Errorf("dropout rate must be in [0, 1), got %g", l.Rate)
+		}
+		return NewDropout(l.Rate, n.rng), nil
+	case "batchnorm":
+		for _, x := range []struct {
+			name string
+			xs   []float64
+		}{{"gains", l.Gains}, {"biases", l.Biases}, {"running means", l.RunningMean}, {"running variances", l.RunningVar}} {
+			if err := checkLen(x.name, x.xs, l.Inputs); err != nil {
+				return nil, err
+			}
+		}
+		layer := NewBatchNorm(l.Inputs)
+		layer.Momentum, layer.Epsilon = l.Momentum, l.Epsilon
+		copy(layer.gamma, l.Gains)
+		copy(layer.beta, l.Biases)
+		copy(layer.RunningMean, l.RunningMean)
+		copy(layer.RunningVar, l.RunningVar)
+		return layer, nil
+	case "layernorm":
+		if err := checkLen("gains", l.Gains, l.Inputs); err != nil {
+			return nil, err
+		}
+		if err := checkLen("biases", l.Biases, l.Inputs); err != nil {
+			return nil, err
+		}
+		layer := NewLayerNorm(l.Inputs)
+		layer.Epsilon = l.Epsilon
+		copy(layer.gain, l.Gains)
+		copy(layer.bias, l.Biases)
+		return layer, nil
+	}
+	return nil, fmt.Errorf("unknown layer type %q", l.Type)
+}
+
+// SaveJSON writes the architecture and weights of the network as an indented JSON document.
+// Only the layers of this package can be saved.
+func (n *Network) SaveJSON(w io.Writer) error {
+	s, err := n.saved()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// LoadJSON reads a network written by SaveJSON. The network is in inference mode.
+func LoadJSON(r io.Reader) (*Network, error) {
+	var s savedNetwork
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("nn: %w", err)
+	}
+	return s.network()
+}
+
+// SaveBinary writes the architecture and weights of the network in a compact binary format:
+// the magic "GONN", the format version as a uint16, then the fields of every layer, all little-endian.
+// Only the layers of this package can be saved.
+func (n *Network) SaveBinary(w io.Writer) error {
+	s, err := n.saved()
+	if err != nil {
+		return err
+	}
+	buf := bufio.NewWriter(w)
+	bw := &binaryWriter{w: buf}
+	bw.write([]byte(binaryMagic))
+	bw.write(uint16(s.Version))
+	bw.write(s.Seed)
+	bw.write(uint32(len(s.Layers)))
+	for _, l := range s.Layers {
+		bw.writeString(l.Type)
+		bw.writeString(l.Activation)
+		bw.write([]uint32{uint32(l.Inputs), uint32(l.Outputs)})
+		bw.write([]float64{l.Rate, l.Momentum, l.Epsilon})
+		bw.write(uint32(len(l.Weights)))
+		for _, row := range l.Weights {
+			bw.writeFloats(row)
+		}
+		for _, xs := range [][]float64{l.Biases, l.Gains, l.RunningMean, l.RunningVar} {
+			bw.writeFloats(xs)
+		}
+	}
+	if bw.err != nil {
+		return bw.err
+	}
+	return buf.Flush()
+}
+
+// LoadBinary reads a network written by SaveBinary. The network is in inference mode.
+func LoadBinary(r io.Reader) (*Network, error) {
+	br := &binaryReader{r: bufio.NewReader(r)}
+	magic := make([]byte, len(binaryMagic))
+	br.read(magic)
+	if br.err == nil && string(magic) != binaryMagic {
+		return nil, errors.New("nn: not a saved network")
+	}
+	var version uint16
+	br.read(&version)
+	if br.err == nil && (version < 1 || version > FormatVersion) {
+		return nil, &VersionError{What: "format", Version: int(version), Max: FormatVersion}
+	}
+
+	s := savedNetwork{Version: int(version)}
+	br.read(&s.Seed)
+	numLayers := br.readLen()
+	for i := 0; i < numLayers && br.err == nil; i++ {
+		var l savedLayer
+		l.Type = br.readString()
+		l.Activation = br.readString()
+		l.Inputs, l.Outputs = br.readLen(), br.readLen()
+		floats := make([]float64, 3)
+		br.read(floats)
+		l.Rate, l.Momentum, l.Epsilon = floats[0], floats[1], floats[2]
+		numRows := br.readLen()
+		for j := 0; j < numRows && br.err == nil; j++ {
+			l.Weights = append(l.Weights, br.readFloats())
+		}
+		l.Biases, l.Gains, l.RunningMean, l.RunningVar = br.readFloats(), br.readFloats(), br.readFloats(), br.readFloats()
+		s.Layers = append(s.Layers, l)
+	}
+	if br.err != nil {
+		return nil, fmt.Errorf("nn: %w", br.err)
+	}
+	return s.network()
+}
+
+// Save writes the network to the file at path, as JSON if path ends in .json and in the binary format otherwise.
+func (n *Network) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(path, ".json") {
+		err = n.SaveJSON(f)
+	} else {
+		err = n.SaveBinary(f)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Load reads a network from a file written by Save.
+func Load(path string) (*Network, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if strings.HasSuffix(path, ".json") {
+		return LoadJSON(f)
+	}
+	return LoadBinary(f)
+}
+
+// binaryWriter writes little-endian values and keeps the first error, so that it only needs checking at the end.
+type binaryWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (b *binaryWriter) write(v any) {
+	if b.err == nil {
+		b.err = binary.Write(b.w, binary.LittleEndian, v)
+	}
+}
+
+func (b *binaryWriter) writeString(s string) {
+	b.write(uint32(len(s)))
+	b.write([]byte(s))
+}
+
+func (b *binaryWriter) writeFloats(xs []float64) {
+	b.write(uint32(len(xs)))
+	b.write(xs)
+}
+
+// binaryReader reads what binaryWriter writes and keeps the first error.
+type binaryReader struct {
+	r   io.Reader
+	err error
+}
+
+// maxLen bounds the lengths and sizes read, so that a corrupted file cannot make LoadBinary allocate without limit.
+const maxLen = 1 << 28
+
+// floatsChunk is the number of floats readFloats allocates at a time, so that a length larger than
+// the data actually present fails at the end of the file rather than with a large allocation.
+const floatsChunk = 1 << 12
+
+// maxStringLen bounds the length of the strings read, which are short names of layer types and activations.
+const maxStringLen = 64
+
+func (b *binaryReader) read(v any) {
+	if b.err == nil {
+		b.err = binary.Read(b.r, binary.LittleEndian, v)
+	}
+}
+
+func (b *binaryReader) readLen() int {
+	var n uint32
+	b.read(&n)
+	if b.err == nil && n > maxLen {
+		b.err = fmt.Errorf("length %d out of range", n)
+	}
+	if b.err != nil {
+		return 0
+	}
+	return int(n)
+}
+
+func (b *binaryReader) readString() string {
+	n := b.readLen()
+	if b.err == nil && n > maxStringLen {
+		b.err = fmt.Errorf("string length %d out of range", n)
+		return ""
+	}
+	s := make([]byte, n)
+	b.read(s)
+	return string(s)
+}
+
+func (b *binaryReader) readFloats() []float64 {
+	n := b.readLen()
+	if n == 0 {
+		return nil
+	}
+	var xs []float64
+	for len(xs) < n && b.err == nil {
+		chunk := make([]float64, min(n-len(xs), floatsChunk))
+		b.read(chunk)
+		xs = append(xs, chunk...)
+	}
+	return xs
+}
@@ -0,0 +1,110 @@
+package nn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNetwork_Save(t *testing.T) {
+	inputs := [][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}}
+	targets := [][]float64{{1, 0}, {0, 1}, {0, 1}, {1, 0}}
+	net := NewNetwork()
+	net.SetSeed(1)
+	net.AddActivation(ASoftmax)
+	net.AddLayer(3, 2, ASigmoid, WithInitializer(GlorotUniform))
+	net.AddLayerNorm(3)
+	net.AddDropout(0.1)
+	net.AddBatchNorm(3)
+	net.AddLayer(2, 3, ATanh, WithInitializer(GlorotUniform))
+	net.Fit(inputs, targets, TrainOptions{Epochs: 5, BatchSize: 2, LearningRate: 0.1})
+
+	dir := t.TempDir()
+	for _, name := range []string{"net.json", "net.bin"} {
+		path := filepath.Join(dir, name)
+		if err := net.Save(path); err != nil {
+			t.Fatalf("%s: expected no error, but got %v", name, err)
+		}
+		loaded, err := Load(path)
+		if err != nil {
+			t.Fatalf("%s: expected no error, but got %v", name, err)
+		}
+		if loaded.Seed() != 1 || len(loaded.layers) != len(net.layers) {
+			t.Fatalf("%s: expected seed 1 and %d layers, but got %d and %d", name, len(net.layers), loaded.Seed(), len(loaded.layers))
+		}
+		for i := range inputs {
+			expected, actual := net.FeedForward(inputs[i]), loaded.FeedForward(inputs[i])
+			for j := range expected {
+				if expected[j] != actual[j] {
+					t.Errorf("%s: expected outputs %v for %v, but got %v", name, expected, inputs[i], actual)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestLoad_Version(t *testing.T) {
+	var versionErr *VersionError
+	if _, err := LoadJSON(strings.NewReader(`{"version": 99, "layers": []}`)); !errors.As(err, &versionErr) || versionErr.Version != 99 {
+		t.Errorf("Expected a *VersionError for version 99, but got %v", err)
+	}
+
+	var buf bytes.Buffer
+	net := NewNetwork()
+	net.AddLayer(2, 1, ASigmoid)
+	if err := net.SaveBinary(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	data[4] = 99 // The version follows the magic.
+	if _, err := LoadBinary(bytes.NewReader(data)); !errors.As(err, &versionErr) {
+		t.Errorf("Expected a *VersionError, but got %v", err)
+	}
+	if _, err := LoadBinary(strings.NewReader("not a network")); err == nil {
+		t.Error("Expected an error for a file that is not a network")
+	}
+}
+
+func TestLoad_InvalidLayer(t *testing.T) {
+	for _, doc := range []string{
+		`{"version": 1, "layers": [{"type": "batchnorm", "inputs": 1000000000000000}]}`,
+		`{"version": 1, "layers": [{"type": "layernorm", "inputs": 1000000000000000}]}`,
+		`{"version": 1, "layers": [{"type": "dropout", "rate": -1}]}`,
+		`{"version": 1, "layers": [{"type": "dropout", "rate": 1}]}`,
+		`{"version": 1, "layers": [{"type": "dense", "activation": "sigmoid", "inputs": 1000000000000000, "outputs": 1, "weights": [[1]], "biases": [0]}]}`,
+	} {
+		if _, err := LoadJSON(strings.NewReader(doc)); err == nil {
+			t.Errorf("Expected an error for %s", doc)
+		}
+	}
+
+	var buf bytes.Buffer
+	net := NewNetwork()
+	net.AddLayer(2, 1, ASigmoid)
+	if err := net.SaveBinary(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// Magic, version, seed and number of layers, then the type and activation of the first layer.
+	offset := 4 + 2 + 8 + 4 + 4 + len("dense") + 4 + len("sigmoid")
+	for _, size := range []uint32{maxLen + 1, maxLen} {
+		data := bytes.Clone(buf.Bytes())
+		binary.LittleEndian.PutUint32(data[offset:], size)
+		if _, err := LoadBinary(bytes.NewReader(data)); err == nil {
+			t.Errorf("Expected an error for %d inputs", size)
+		}
+	}
+
+	// A truncated file announcing a huge string fails without allocating it.
+	var truncated bytes.Buffer
+	truncated.WriteString(binaryMagic)
+	binary.Write(&truncated, binary.LittleEndian, uint16(FormatVersion))
+	binary.Write(&truncated, binary.LittleEndian, int64(0))
+	binary.Write(&truncated, binary.LittleEndian, []uint32{1, maxLen})
+	if _, err := LoadBinary(&truncated); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("Expected a length out of range, but got %v", err)
+	}
+}
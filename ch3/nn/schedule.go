@@ -0,0 +1,160 @@
+package nn
+
+import (
+	"math"
+	"strings"
+)
+
+// Schedule gives the learning rate of every update of a training run.
+// A Schedule that also implements Callback is notified by Network.Fit, which lets it react to metrics.
+type Schedule interface {
+	// Rate returns the learning rate of an update made during epoch (counted from 0),
+	// step being the number of updates made since the start of training.
+	Rate(epoch, step int) float64
+}
+
+// ConstantRate keeps the learning rate at a fixed value.
+type ConstantRate float64
+
+// Rate implements Schedule.
+func (c ConstantRate) Rate(epoch, step int) float64 {
+	return float64(c)
+}
+
+// StepDecay multiplies the learning rate by Factor every Every epochs.
+type StepDecay struct {
+	Initial float64 // Learning rate of the first epochs.
+	Factor  float64 // Multiplier applied at every decay, such as 0.5.
+	Every   int     // Number of epochs between two decays. Values below 1 mean no decay.
+}
+
+// Rate implements Schedule.
+func (s StepDecay) Rate(epoch, step int) float64 {
+	if s.Every < 1 {
+		return s.Initial
+	}
+	return s.Initial * math.Pow(s.Factor, float64(epoch/s.Every))
+}
+
+// ExponentialDecay multiplies the learning rate by Factor every epoch.
+type ExponentialDecay struct {
+	Initial float64 // Learning rate of the first epoch.
+	Factor  float64 // Multiplier applied every epoch, such as 0.95.
+}
+
+// Rate implements Schedule.
+func (s ExponentialDecay) Rate(epoch, step int) float64 {
+	return s.Initial * math.Pow(s.Factor, float64(epoch))
+}
+
+// CosineRestarts anneals the learning rate from Max to Min along a half cosine, then restarts from Max.
+// The first cycle lasts Period epochs and every following cycle is Mult times longer.
+type CosineRestarts struct {
+	Max    float64 // Learning rate at the start of every cycle.
+	Min    float64 // Learning rate reached at the end of every cycle.
+	Period int     // Length of the first cycle in epochs. Values below 1 mean no annealing: the rate stays at Max.
+	Mult   float64 // Growth of the cycle length. Values below 1 mean 1, i.e. cycles of equal length.
+}
+
+// Rate implements Schedule.
+func (s CosineRestarts) Rate(epoch, step int) float64 {
+	if s.Period < 1 {
+		return s.Max
+	}
+	mult := math.Max(s.Mult, 1)
+	t, period := float64(epoch), float64(s.Period)
+	for t >= period { // Find the position of epoch in its cycle.
+		t -= period
+		period *= mult
+	}
+	return s.Min + 0.5*(s.Max-s.Min)*(1+math.Cos(math.Pi*t/period))
+}
+
+// Warmup ramps the learning rate up linearly during the first Steps updates, up to the rate of Schedule.
+type Warmup struct {
+	Steps    int      // Number of updates of the warmup.
+	Schedule Schedule // Schedule followed during and after the warmup.
+}
+
+// Rate implements Schedule.
+func (s Warmup) Rate(epoch, step int) float64 {
+	rate := s.Schedule.Rate(epoch, step)
+	if step < s.Steps {
+		rate *= float64(step+1) / float64(s.Steps)
+	}
+	return rate
+}
+
+// forward calls f with the wrapped schedule if it is a Callback, so that Warmup can wrap ReduceOnPlateau.
+func (s Warmup) forward(f func(c Callback)) {
+	if c, ok := s.Schedule.(Callback); ok {
+		f(c)
+	}
+}
+
+// OnTrainBegin implements Callback.
+func (s Warmup) OnTrainBegin(p *Progress) { s.forward(func(c Callback) { c.OnTrainBegin(p) }) }
+
+// OnTrainEnd implements Callback.
+func (s Warmup) OnTrainEnd(p *Progress) { s.forward(func(c Callback) { c.OnTrainEnd(p) }) }
+
+// OnEpochBegin implements Callback.
+func (s Warmup) OnEpochBegin(p *Progress) { s.forward(func(c Callback) { c.OnEpochBegin(p) }) }
+
+// OnEpochEnd implements Callback.
+func (s Warmup) OnEpochEnd(p *Progress) { s.forward(func(c Callback) { c.OnEpochEnd(p) }) }
+
+// OnBatchEnd implements Callback.
+func (s Warmup) OnBatchEnd(p *Progress) { s.forward(func(c Callback) { c.OnBatchEnd(p) }) }
+
+// ReduceOnPlateau divides the learning rate when a monitored metric stops improving.
+// It is a Callback as well as a Schedule, and keeps its state in exported fields.
+type ReduceOnPlateau struct {
+	nopCallback
+
+	Monitor  string  // Metric to watch: "val_loss" (the default), "loss" or "val_accuracy". Accuracies are maximized, losses minimized.
+	Factor   float64 // Multiplier applied to the learning rate on a plateau, such as 0.5.
+	Patience int     // Number of epochs without improvement before reducing the learning rate.
+	MinDelta float64 // Smallest change of the metric that counts as an improvement.
+	MinRate  float64 // The learning rate is never reduced below MinRate.
+
+	Current float64 // State: current learning rate, starting at the initial learning rate.
+	Best    float64 // State: best value of the metric so far, negated for accuracies. Starts at +Inf.
+	Wait    int     // State: number of epochs since the last improvement or reduction.
+}
+
+// NewReduceOnPlateau creates a ReduceOnPlateau schedule starting at rate initial and watching "val_loss".
+func NewReduceOnPlateau(initial, factor float64, patience int) *ReduceOnPlateau {
+	return &ReduceOnPlateau{Monitor: "val_loss", Factor: factor, Patience: patience, Current: initial, Best: math.Inf(1)}
+}
+
+// Rate implements Schedule.
+func (s *ReduceOnPlateau) Rate(epoch, step int) float64 {
+	return s.Current
+}
+
+// OnEpochEnd implements Callback.
+func (s *ReduceOnPlateau) OnEpochEnd(p *Progress) {
+	monitor := s.Monitor
+	if monitor == "" {
+		monitor = "val_loss"
+	}
+	value, ok := p.Metrics[monitor]
+	if !ok {
+		return
+	}
+	if strings.HasSuffix(monitor, "accuracy") {
+		value = -value // Turn the accuracy into something to minimize.
+	}
+
+	if value < s.Best-s.MinDelta {
+		s.Best = value
+		s.Wait = 0
+		return
+	}
+	s.Wait++
+	if s.Wait >= s.Patience {
+		s.Current = math.Max(s.Current*s.Factor, s.MinRate)
+		s.Wait = 0
+	}
+}
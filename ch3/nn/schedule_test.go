@@ -0,0 +1,50 @@
+package nn
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSchedules(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		epoch    int
+		step     int
+		expected float64
+	}{
+		{"constant", ConstantRate(0.1), 5, 50, 0.1},
+		{"step", StepDecay{Initial: 1, Factor: 0.5, Every: 10}, 25, 0, 0.25},
+		{"step-every-zero", StepDecay{Initial: 1, Factor: 0.5, Every: 0}, 25, 0, 1},
+		{"step-every-negative", StepDecay{Initial: 1, Factor: 0.5, Every: -3}, 25, 0, 1},
+		{"exponential", ExponentialDecay{Initial: 1, Factor: 0.9}, 2, 0, 0.81},
+		{"cosine-start", CosineRestarts{Max: 1, Min: 0, Period: 10, Mult: 2}, 0, 0, 1},
+		{"cosine-middle", CosineRestarts{Max: 1, Min: 0, Period: 10, Mult: 2}, 5, 0, 0.5},
+		{"cosine-restart", CosineRestarts{Max: 1, Min: 0, Period: 10, Mult: 2}, 10, 0, 1},
+		{"cosine-second-cycle", CosineRestarts{Max: 1, Min: 0, Period: 10, Mult: 2}, 20, 0, 0.5},
+		{"cosine-period-zero", CosineRestarts{Max: 1, Min: 0, Period: 0, Mult: 2}, 5, 0, 1},
+		{"cosine-period-negative", CosineRestarts{Max: 1, Min: 0, Period: -1, Mult: 2}, 5, 0, 1},
+		{"warmup", Warmup{Steps: 4, Schedule: ConstantRate(1)}, 0, 1, 0.5},
+		{"warmup-done", Warmup{Steps: 4, Schedule: ConstantRate(1)}, 0, 4, 1},
+	}
+	for _, tt := range tests {
+		if rate := tt.schedule.Rate(tt.epoch, tt.step); math.Abs(rate-tt.expected) > 1e-9 {
+			t.Errorf("%s: expected rate %f, but got %f", tt.name, tt.expected, rate)
+		}
+	}
+}
+
+func TestReduceOnPlateau(t *testing.T) {
+	s := NewReduceOnPlateau(1, 0.5, 2)
+	var rates []float64
+	for epoch, loss := range []float64{3, 2, 2, 2, 1, 1, 1} {
+		s.OnEpochEnd(&Progress{Epoch: epoch, Metrics: map[string]float64{"val_loss": loss}})
+		rates = append(rates, s.Rate(epoch, 0))
+	}
+	expected := []float64{1, 1, 1, 0.5, 0.5, 0.5, 0.25}
+	for i := range expected {
+		if rates[i] != expected[i] {
+			t.Fatalf("Expected rates %v, but got %v", expected, rates)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Package preprocess provides transformers that turn raw columns into network inputs and targets.
+// Every transformer is fitted on training data and keeps what it learned in exported fields,
+// so that it can be saved alongside a model and applied again at inference time.
+package preprocess
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mreza101/gonn/ch3/tensor"
+)
+
+// UnknownPolicy decides how an encoder treats a category it did not see during Fit.
+type UnknownPolicy int
+
+const (
+	UnknownError  UnknownPolicy = iota // Return an *UnknownCategoryError.
+	UnknownIgnore                      // Encode as all zeros (one-hot) or -1 (label).
+)
+
+// UnknownCategoryError reports a value that is not part of the fitted vocabulary.
+type UnknownCategoryError struct {
+	Value string
+}
+
+func (e *UnknownCategoryError) Error() string {
+	return fmt.Sprintf("preprocess: unknown category %q", e.Value)
+}
+
+// vocabulary returns the sorted distinct values.
+func vocabulary(values []string) []string {
+	seen := make(map[string]bool)
+	var categories []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			categories = append(categories, v)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+func indexOf(categories []string, value string) int {
+	for i, c := range categories {
+		if c == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// OneHotEncoder encodes each category as a vector with a single 1 at the category's index.
+type OneHotEncoder struct {
+	Categories []string      // Fitted vocabulary, sorted.
+	Unknown    UnknownPolicy // Treatment of categories not in the vocabulary.
+}
+
+// NewOneHotEncoder creates an unfitted one-hot encoder.
+func NewOneHotEncoder(unknown UnknownPolicy) *OneHotEncoder {
+	return &OneHotEncoder{Unknown: unknown}
+}
+
+// Fit learns the vocabulary from values.
+func (e *OneHotEncoder) Fit(values []string) {
+	e.Categories = vocabulary(values)
+}
+
+// Transform encodes values into one row per value and one column per category.
+func (e *OneHotEncoder) Transform(values []string) ([][]float64, error) {
+	result := tensor.NewMatrix(len(values), len(e.Categories))
+	for i, v := range values {
+		c := indexOf(e.Categories, v)
+		if c < 0 {
+			if e.Unknown == UnknownError {
+				return nil, &UnknownCategoryError{Value: v}
+			}
+			continue
+		}
+		result[i][c] = 1
+	}
+	return result, nil
+}
+
+// FitTransform fits the encoder on values and encodes them.
+func (e *OneHotEncoder) FitTransform(values []string) ([][]float64, error) {
+	e.Fit(values)
+	return e.Transform(values)
+}
+
+// InverseTransform maps each row back to the category with the largest value,
+// so it also accepts network outputs such as class probabilities or logits. Rows of zeros, which encode
+// unknown categories under UnknownIgnore, yield an empty string, as does an unfitted encoder.
+func (e *OneHotEncoder) InverseTransform(rows [][]float64) []string {
+	result := make([]string, len(rows))
+	for i, row := range rows {
+		if c := tensor.ArgMax(row); c < len(e.Categories) && !allZeros(row) {
+			result[i] = e.Categories[c]
+		}
+	}
+	return result
+}
+
+// allZeros reports whether every value of row is 0, which includes an empty row.
+func allZeros(row []float64) bool {
+	for _, v := range row {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// LabelEncoder encodes each category as its index in the vocabulary, in a single column.
+type LabelEncoder struct {
+	Categories []string      // Fitted vocabulary, sorted.
+	Unknown    UnknownPolicy // Treatment of categories not in the vocabulary.
+}
+
+// NewLabelEncoder creates an unfitted label encoder.
+func NewLabelEncoder(unknown UnknownPolicy) *LabelEncoder {
+	return &LabelEncoder{Unknown: unknown}
+}
+
+// Fit learns the vocabulary from values.
+func (e *LabelEncoder) Fit(values []string) {
+	e.Categories = vocabulary(values)
+}
+
+// Transform encodes values into one row per value with a single column holding the category index.
+func (e *LabelEncoder) Transform(values []string) ([][]float64, error) {
+	result := tensor.NewMatrix(len(values), 1)
+	for i, v := range values {
+		c := indexOf(e.Categories, v)
+		if c < 0 && e.Unknown == UnknownError {
+			return nil, &UnknownCategoryError{Value: v}
+		}
+		result[i][0] = float64(c)
+	}
+	return result, nil
+}
+
+// FitTransform fits the encoder on values and encodes them.
+func (e *LabelEncoder) FitTransform(values []string) ([][]float64, error) {
+	e.Fit(values)
+	return e.Transform(values)
+}
+
+// InverseTransform maps the first column of each row, rounded to the nearest index, back to its category.
+// Indices outside the vocabulary yield an empty string.
+func (e *LabelEncoder) InverseTransform(rows [][]float64) []string {
+	result := make([]string, len(rows))
+	for i, row := range rows {
+		c := int(row[0] + 0.5)
+		if row[0] >= -0.5 && c < len(e.Categories) {
+			result[i] = e.Categories[c]
+		}
+	}
+	return result
+}
@@ -0,0 +1,57 @@
+package preprocess
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOneHotEncoder(t *testing.T) {
+	enc := NewOneHotEncoder(UnknownError)
+	encoded, err := enc.FitTransform([]string{"Gentoo", "Adelie", "Chinstrap", "Adelie"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]float64{{0, 0, 1}, {1, 0, 0}, {0, 1, 0}, {1, 0, 0}}
+	if !reflect.DeepEqual(encoded, expected) {
+		t.Errorf("Expected %v, but got %v", expected, encoded)
+	}
+
+	decoded := enc.InverseTransform([][]float64{{0.1, 0.7, 0.2}, {0.8, 0.1, 0.1}})
+	if !reflect.DeepEqual(decoded, []string{"Chinstrap", "Adelie"}) {
+		t.Errorf("Expected [Chinstrap Adelie], but got %v", decoded)
+	}
+
+	var unknown *UnknownCategoryError
+	if _, err := enc.Transform([]string{"Emperor"}); !errors.As(err, &unknown) {
+		t.Errorf("Expected UnknownCategoryError, but got %v", err)
+	}
+	enc.Unknown = UnknownIgnore
+	if encoded, _ := enc.Transform([]string{"Emperor"}); !reflect.DeepEqual(encoded, [][]float64{{0, 0, 0}}) {
+		t.Errorf("Expected all zeros, but got %v", encoded)
+	}
+	if decoded := enc.InverseTransform([][]float64{{0, 0, 0}, {0, 1, 0}, {-3, -1, -2}}); !reflect.DeepEqual(decoded, []string{"", "Chinstrap", "Chinstrap"}) {
+		t.Errorf("Expected [ Chinstrap Chinstrap], but got %q", decoded)
+	}
+
+	if decoded := NewOneHotEncoder(UnknownError).InverseTransform([][]float64{{1, 0}}); !reflect.DeepEqual(decoded, []string{""}) {
+		t.Errorf("Expected an empty string from an unfitted encoder, but got %q", decoded)
+	}
+}
+
+func TestLabelEncoder(t *testing.T) {
+	enc := NewLabelEncoder(UnknownIgnore)
+	encoded, err := enc.FitTransform([]string{"female", "male", "female"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(encoded, [][]float64{{0}, {1}, {0}}) {
+		t.Errorf("Expected [[0] [1] [0]], but got %v", encoded)
+	}
+	if encoded, _ := enc.Transform([]string{"NA"}); encoded[0][0] != -1 {
+		t.Errorf("Expected -1 for unknown category, but got %v", encoded[0][0])
+	}
+	if decoded := enc.InverseTransform([][]float64{{0.9}, {-1}}); !reflect.DeepEqual(decoded, []string{"male", ""}) {
+		t.Errorf("Expected [male ], but got %v", decoded)
+	}
+}
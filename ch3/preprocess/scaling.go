@@ -0,0 +1,131 @@
+package preprocess
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/mreza101/gonn/ch3/tensor"
+)
+
+// Scaler rescales each column of a matrix with statistics learned during Fit.
+type Scaler interface {
+	Fit(x [][]float64)
+	Transform(x [][]float64) [][]float64
+	InverseTransform(x [][]float64) [][]float64
+}
+
+// Affine maps each column j to (x[j] - Center[j]) / Scale[j]. It holds the fitted statistics of all scalers.
+type Affine struct {
+	Center []float64 `json:"center"`
+	Scale  []float64 `json:"scale"`
+}
+
+// Transform rescales x into a new matrix.
+func (a *Affine) Transform(x [][]float64) [][]float64 {
+	result := tensor.NewMatrix(len(x), len(a.Center))
+	for i := range x {
+		for j := range a.Center {
+			result[i][j] = (x[i][j] - a.Center[j]) / a.Scale[j]
+		}
+	}
+	return result
+}
+
+// InverseTransform undoes Transform.
+func (a *Affine) InverseTransform(x [][]float64) [][]float64 {
+	result := tensor.NewMatrix(len(x), len(a.Center))
+	for i := range x {
+		for j := range a.Center {
+			result[i][j] = x[i][j]*a.Scale[j] + a.Center[j]
+		}
+	}
+	return result
+}
+
+// fit computes Center and Scale column by column. Columns with a zero scale are left unscaled.
+func (a *Affine) fit(x [][]float64, stats func(col []float64) (center, scale float64)) {
+	cols := tensor.Transpose(x)
+	a.Center = make([]float64, len(cols))
+	a.Scale = make([]float64, len(cols))
+	for j, col := range cols {
+		a.Center[j], a.Scale[j] = stats(col)
+		if a.Scale[j] == 0 {
+			a.Scale[j] = 1
+		}
+	}
+}
+
+// StandardScaler rescales each column to zero mean and unit variance.
+type StandardScaler struct{ Affine }
+
+// Fit learns the mean and standard deviation of each column.
+func (s *StandardScaler) Fit(x [][]float64) {
+	s.fit(x, func(col []float64) (float64, float64) {
+		return tensor.Mean(col), math.Sqrt(tensor.Variance(col))
+	})
+}
+
+// MinMaxScaler rescales each column to the range [0, 1].
+type MinMaxScaler struct{ Affine }
+
+// Fit learns the minimum and maximum of each column.
+func (s *MinMaxScaler) Fit(x [][]float64) {
+	s.fit(x, func(col []float64) (float64, float64) {
+		lo := tensor.Min(col)
+		return lo, tensor.Max(col) - lo
+	})
+}
+
+// RobustScaler centers each column on its median and scales it by its interquartile range,
+// so that outliers have little influence on the fitted statistics.
+type RobustScaler struct{ Affine }
+
+// Fit learns the median and interquartile range of each column.
+func (s *RobustScaler) Fit(x [][]float64) {
+	s.fit(x, func(col []float64) (float64, float64) {
+		return tensor.Quantile(col, 0.5), tensor.Quantile(col, 0.75) - tensor.Quantile(col, 0.25)
+	})
+}
+
+type savedScaler struct {
+	Type string `json:"type"`
+	Affine
+}
+
+// SaveScaler writes the fitted statistics of s as JSON.
+func SaveScaler(w io.Writer, s Scaler) error {
+	saved := savedScaler{}
+	switch s := s.(type) {
+	case *StandardScaler:
+		saved = savedScaler{Type: "standard", Affine: s.Affine}
+	case *MinMaxScaler:
+		saved = savedScaler{Type: "minmax", Affine: s.Affine}
+	case *RobustScaler:
+		saved = savedScaler{Type: "robust", Affine: s.Affine}
+	default:
+		return fmt.Errorf("preprocess: cannot save scaler of type %T", s)
+	}
+	return json.NewEncoder(w).Encode(saved)
+}
+
+// LoadScaler reads a scaler written by SaveScaler.
+func LoadScaler(r io.Reader) (Scaler, error) {
+	var saved savedScaler
+	if err := json.NewDecoder(r).Decode(&saved); err != nil {
+		return nil, fmt.Errorf("preprocess: %w", err)
+	}
+	if len(saved.Center) != len(saved.Scale) {
+		return nil, fmt.Errorf("preprocess: scaler has %d centers but %d scales", len(saved.Center), len(saved.Scale))
+	}
+	switch saved.Type {
+	case "standard":
+		return &StandardScaler{saved.Affine}, nil
+	case "minmax":
+		return &MinMaxScaler{saved.Affine}, nil
+	case "robust":
+		return &RobustScaler{saved.Affine}, nil
+	}
+	return nil, fmt.Errorf("preprocess: unknown scaler type %q", saved.Type)
+}
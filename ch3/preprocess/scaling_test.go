@@ -0,0 +1,45 @@
+package preprocess
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+// Function to approximate floating point comparison
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 0.000001
+}
+
+func TestScalers(t *testing.T) {
+	x := [][]float64{{39.1, 3750}, {46.2, 4650}, {46.5, 3500}, {40.0, 3500}}
+	for _, s := range []Scaler{&StandardScaler{}, &MinMaxScaler{}, &RobustScaler{}} {
+		s.Fit(x)
+
+		// The scaler must survive a save and load round trip.
+		var buf bytes.Buffer
+		if err := SaveScaler(&buf, s); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadScaler(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		restored := loaded.InverseTransform(s.Transform(x))
+		for i := range x {
+			for j := range x[i] {
+				if !approxEqual(restored[i][j], x[i][j]) {
+					t.Errorf("%T: expected %f, but got %f", s, x[i][j], restored[i][j])
+				}
+			}
+		}
+	}
+
+	minMax := &MinMaxScaler{}
+	minMax.Fit(x)
+	scaled := minMax.Transform(x)
+	if scaled[2][0] != 1 || scaled[0][0] != 0 || scaled[1][1] != 1 {
+		t.Errorf("Expected values in [0, 1], but got %v", scaled)
+	}
+}
@@ -0,0 +1,44 @@
+package tensor
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrShapeMismatch is matched, with errors.Is, by the errors returned for operands of incompatible shapes.
+var ErrShapeMismatch = errors.New("shape mismatch")
+
+// ShapeError reports an operand whose shape does not fit an operation.
+type ShapeError struct {
+	Op       string // Operation, such as "Add" or "MulMatrix".
+	What     string // Dimension that does not fit, such as "elements of b" or "rows of b".
+	Expected int
+	Actual   int
+}
+
+func (e *ShapeError) Error() string {
+	return fmt.Sprintf("tensor: %s: expected %d %s, got %d", e.Op, e.Expected, e.What, e.Actual)
+}
+
+// Is makes errors.Is(err, ErrShapeMismatch) report true for a *ShapeError.
+func (e *ShapeError) Is(target error) bool {
+	return target == ErrShapeMismatch
+}
+
+// checkLen returns a *ShapeError if actual differs from expected.
+func checkLen(op, what string, expected, actual int) error {
+	if expected != actual {
+		return &ShapeError{Op: op, What: what, Expected: expected, Actual: actual}
+	}
+	return nil
+}
+
+// checkRows returns a *ShapeError unless every row of m has n elements.
+func checkRows(op, name string, m [][]float64, n int) error {
+	for i := range m {
+		if err := checkLen(op, fmt.Sprintf("columns in row %d of %s", i, name), n, len(m[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
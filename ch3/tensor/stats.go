@@ -0,0 +1,72 @@
+package tensor
+
+import (
+	"math"
+	"sort"
+)
+
+// Transpose returns the transpose of a matrix.
+func Transpose(a [][]float64) [][]float64 {
+	if len(a) == 0 {
+		return nil
+	}
+	result := NewMatrix(len(a[0]), len(a))
+	for i := range a {
+		for j := range a[i] {
+			result[j][i] = a[i][j]
+		}
+	}
+	return result
+}
+
+// Sum returns the sum of the elements of a vector.
+func Sum(a []float64) float64 {
+	result := 0.0
+	for i := range a {
+		result += a[i]
+	}
+	return result
+}
+
+// Mean returns the arithmetic mean of a vector.
+func Mean(a []float64) float64 {
+	return Sum(a) / float64(len(a))
+}
+
+// Variance returns the population variance of a vector.
+func Variance(a []float64) float64 {
+	mean := Mean(a)
+	result := 0.0
+	for i := range a {
+		result += (a[i] - mean) * (a[i] - mean)
+	}
+	return result / float64(len(a))
+}
+
+// Min returns the smallest element of a vector.
+func Min(a []float64) float64 {
+	result := math.Inf(1)
+	for i := range a {
+		result = math.Min(result, a[i])
+	}
+	return result
+}
+
+// Max returns the largest element of a vector.
+func Max(a []float64) float64 {
+	result := math.Inf(-1)
+	for i := range a {
+		result = math.Max(result, a[i])
+	}
+	return result
+}
+
+// Quantile returns the q-th quantile (0 <= q <= 1) of a vector, interpolating linearly between elements.
+func Quantile(a []float64, q float64) float64 {
+	sorted := append([]float64{}, a...)
+	sort.Float64s(sorted)
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (pos-float64(lo))*(sorted[hi]-sorted[lo])
+}
@@ -1,10 +1,13 @@
 // Package tensor provides functions for manipulating vectors and matrices.
 package tensor
 
+import "fmt"
+
 func NewVector(n int) []float64 {
 	return make([]float64, n)
 }
 
+// Add returns the element-wise sum of two vectors of the same length.
 func Add(a, b []float64) []float64 {
 	result := NewVector(len(a))
 	for i := range a {
@@ -13,6 +16,15 @@ func Add(a, b []float64) []float64 {
 	return result
 }
 
+// AddChecked is Add returning a *ShapeError if a and b have different lengths.
+func AddChecked(a, b []float64) ([]float64, error) {
+	if err := checkLen("Add", "elements in b", len(a), len(b)); err != nil {
+		return nil, err
+	}
+	return Add(a, b), nil
+}
+
+// Sub returns the element-wise difference of two vectors of the same length.
 func Sub(a, b []float64) []float64 {
 	result := NewVector(len(a))
 	for i := range a {
@@ -21,6 +33,15 @@ func Sub(a, b []float64) []float64 {
 	return result
 }
 
+// SubChecked is Sub returning a *ShapeError if a and b have different lengths.
+func SubChecked(a, b []float64) ([]float64, error) {
+	if err := checkLen("Sub", "elements in b", len(a), len(b)); err != nil {
+		return nil, err
+	}
+	return Sub(a, b), nil
+}
+
+// Mul returns the element-wise product of two vectors of the same length.
 func Mul(a, b []float64) []float64 {
 	result := NewVector(len(a))
 	for i := range a {
@@ -29,6 +50,15 @@ func Mul(a, b []float64) []float64 {
 	return result
 }
 
+// MulChecked is Mul returning a *ShapeError if a and b have different lengths.
+func MulChecked(a, b []float64) ([]float64, error) {
+	if err := checkLen("Mul", "elements in b", len(a), len(b)); err != nil {
+		return nil, err
+	}
+	return Mul(a, b), nil
+}
+
+// Dot returns the dot product of two vectors of the same length.
 func Dot(a, b []float64) float64 {
 	result := 0.0
 	for i := range a {
@@ -37,6 +67,14 @@ func Dot(a, b []float64) float64 {
 	return result
 }
 
+// DotChecked is Dot returning a *ShapeError if a and b have different lengths.
+func DotChecked(a, b []float64) (float64, error) {
+	if err := checkLen("Dot", "elements in b", len(a), len(b)); err != nil {
+		return 0, err
+	}
+	return Dot(a, b), nil
+}
+
 func Scale(a []float64, s float64) []float64 {
 	result := NewVector(len(a))
 	for i := range a {
@@ -53,6 +91,21 @@ func NewMatrix(m, n int) [][]float64 {
 	return matrix
 }
 
+// checkSameShape returns a *ShapeError unless a and b have the same number of rows and every row of b
+// has as many elements as the same row of a.
+func checkSameShape(op string, a, b [][]float64) error {
+	if err := checkLen(op, "rows in b", len(a), len(b)); err != nil {
+		return err
+	}
+	for i := range a {
+		if err := checkLen(op, fmt.Sprintf("columns in row %d of b", i), len(a[i]), len(b[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddMatrix returns the element-wise sum of two matrices of the same shape.
 func AddMatrix(a, b [][]float64) [][]float64 {
 	result := NewMatrix(len(a), len(a[0]))
 	for i := range a {
@@ -63,6 +116,18 @@ func AddMatrix(a, b [][]float64) [][]float64 {
 	return result
 }
 
+// AddMatrixChecked is AddMatrix returning a *ShapeError if a and b have different shapes.
+func AddMatrixChecked(a, b [][]float64) ([][]float64, error) {
+	if err := checkSameShape("AddMatrix", a, b); err != nil {
+		return nil, err
+	}
+	if len(a) == 0 {
+		return [][]float64{}, nil
+	}
+	return AddMatrix(a, b), nil
+}
+
+// SubMatrix returns the element-wise difference of two matrices of the same shape.
 func SubMatrix(a, b [][]float64) [][]float64 {
 	result := NewMatrix(len(a), len(a[0]))
 	for i := range a {
@@ -73,6 +138,18 @@ func SubMatrix(a, b [][]float64) [][]float64 {
 	return result
 }
 
+// SubMatrixChecked is SubMatrix returning a *ShapeError if a and b have different shapes.
+func SubMatrixChecked(a, b [][]float64) ([][]float64, error) {
+	if err := checkSameShape("SubMatrix", a, b); err != nil {
+		return nil, err
+	}
+	if len(a) == 0 {
+		return [][]float64{}, nil
+	}
+	return SubMatrix(a, b), nil
+}
+
+// MulMatrix returns the matrix product of a and b. Every row of a must have as many elements as b has rows.
 func MulMatrix(a, b [][]float64) [][]float64 {
 	result := NewMatrix(len(a), len(b[0]))
 	for i := range a {
@@ -85,7 +162,22 @@ func MulMatrix(a, b [][]float64) [][]float64 {
 	return result
 }
 
-// MulMatrixVector multiplies a matrix by a vector.
+// MulMatrixChecked is MulMatrix returning a *ShapeError if the rows of a do not have as many elements
+// as b has rows, or the rows of b have different lengths.
+func MulMatrixChecked(a, b [][]float64) ([][]float64, error) {
+	if err := checkRows("MulMatrix", "a", a, len(b)); err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return NewMatrix(len(a), 0), nil
+	}
+	if err := checkRows("MulMatrix", "b", b, len(b[0])); err != nil {
+		return nil, err
+	}
+	return MulMatrix(a, b), nil
+}
+
+// MulMatrixVector multiplies a matrix by a vector. Every row of a must have as many elements as b.
 func MulMatrixVector(a [][]float64, b []float64) []float64 {
 	result := NewVector(len(a))
 	for i := range a {
@@ -96,7 +188,15 @@ func MulMatrixVector(a [][]float64, b []float64) []float64 {
 	return result
 }
 
-// MulVectorMatrix multiplies a vector by a matrix.
+// MulMatrixVectorChecked is MulMatrixVector returning a *ShapeError if a row of a does not have as many elements as b.
+func MulMatrixVectorChecked(a [][]float64, b []float64) ([]float64, error) {
+	if err := checkRows("MulMatrixVector", "a", a, len(b)); err != nil {
+		return nil, err
+	}
+	return MulMatrixVector(a, b), nil
+}
+
+// MulVectorMatrix multiplies a vector by a matrix. b must have as many rows as a has elements.
 func MulVectorMatrix(a []float64, b [][]float64) []float64 {
 	result := NewVector(len(b[0]))
 	for i := range b[0] {
@@ -106,3 +206,61 @@ func MulVectorMatrix(a []float64, b [][]float64) []float64 {
 	}
 	return result
 }
+
+// MulVectorMatrixChecked is MulVectorMatrix returning a *ShapeError if b does not have as many rows
+// as a has elements, or the rows of b have different lengths.
+func MulVectorMatrixChecked(a []float64, b [][]float64) ([]float64, error) {
+	if err := checkLen("MulVectorMatrix", "rows in b", len(a), len(b)); err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return []float64{}, nil
+	}
+	if err := checkRows("MulVectorMatrix", "b", b, len(b[0])); err != nil {
+		return nil, err
+	}
+	return MulVectorMatrix(a, b), nil
+}
+
+// ArgMax returns the index of the largest element of a vector.
+func ArgMax(a []float64) int {
+	maxIndex := 0
+	for i := range a {
+		if a[i] > a[maxIndex] {
+			maxIndex = i
+		}
+	}
+	return maxIndex
+}
+
+// HStack concatenates matrices with the same number of rows side by side.
+func HStack(matrices ...[][]float64) ([][]float64, error) {
+	if len(matrices) == 0 {
+		return nil, nil
+	}
+	for i, m := range matrices {
+		if err := checkLen("HStack", fmt.Sprintf("rows in matrix %d", i), len(matrices[0]), len(m)); err != nil {
+			return nil, err
+		}
+	}
+	result := make([][]float64, len(matrices[0]))
+	for i := range result {
+		for _, m := range matrices {
+			result[i] = append(result[i], m[i]...)
+		}
+	}
+	return result, nil
+}
+
+// Reshape returns a matrix of m rows and n columns that shares its elements with data.
+// The length of data must be m*n.
+func Reshape(data []float64, m, n int) ([][]float64, error) {
+	if err := checkLen("Reshape", "elements in data", m*n, len(data)); err != nil {
+		return nil, err
+	}
+	matrix := make([][]float64, m)
+	for i := range matrix {
+		matrix[i] = data[i*n : (i+1)*n : (i+1)*n]
+	}
+	return matrix, nil
+}
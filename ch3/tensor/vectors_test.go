@@ -0,0 +1,31 @@
+package tensor
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestShapeErrors(t *testing.T) {
+	if _, err := AddChecked([]float64{1, 2}, []float64{1}); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("Expected a shape mismatch, but got %v", err)
+	}
+	_, err := MulMatrixChecked([][]float64{{1, 2}}, [][]float64{{1}, {2}, {3}})
+	if err == nil || err.Error() != "tensor: MulMatrix: expected 3 columns in row 0 of a, got 2" {
+		t.Errorf("Expected an error about the columns of a, but got %v", err)
+	}
+	if _, err := Reshape(make([]float64, 5), 2, 3); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("Expected a shape mismatch, but got %v", err)
+	}
+
+	if _, err := AddMatrixChecked([][]float64{{1, 2}}, [][]float64{{1}}); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("Expected a shape mismatch, but got %v", err)
+	}
+	if d, err := DotChecked([]float64{1, 2}, []float64{3, 4}); err != nil || d != Dot([]float64{1, 2}, []float64{3, 4}) {
+		t.Errorf("Expected 11, but got %v, %v", d, err)
+	}
+
+	result, err := MulMatrixVectorChecked([][]float64{{1, 2}, {3, 4}}, []float64{1, 1})
+	if err != nil || result[0] != 3 || result[1] != 7 {
+		t.Errorf("Expected [3 7], but got %v, %v", result, err)
+	}
+}